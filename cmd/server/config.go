@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration written like `5s` or `1m30s` in config files and flags.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config is the configuration of the server.
+// Defaults are overridden by the config file, then by the environment variables, then by the flags.
+type Config struct {
+	Domain       string `yaml:"domain" toml:"domain"`     // the default domain of addresses.
+	Hostname     string `yaml:"hostname" toml:"hostname"` // the name the SMTP server greets with. defaults to Domain.
+	Secret       string `yaml:"secret" toml:"secret"`     // an admin token for bootstrapping.
+	VerifySender bool   `yaml:"verify_sender" toml:"verify_sender"`
+
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	Listen   ListenConfig   `yaml:"listen" toml:"listen"`
+	TLS      TLSConfig      `yaml:"tls" toml:"tls"`
+	Timeouts TimeoutsConfig `yaml:"timeouts" toml:"timeouts"`
+	Limits   LimitsConfig   `yaml:"limits" toml:"limits"`
+	Log      LogConfig      `yaml:"log" toml:"log"`
+	Forward  ForwardConfig  `yaml:"forward" toml:"forward"`
+}
+
+type DatabaseConfig struct {
+	DSN         string `yaml:"dsn" toml:"dsn"`
+	AutoMigrate bool   `yaml:"auto_migrate" toml:"auto_migrate"`
+}
+
+// ListenConfig holds the addresses to listen on. An empty address disables the listener.
+// Submission and SMTPS are enabled only with TLS.
+type ListenConfig struct {
+	SMTP       string `yaml:"smtp" toml:"smtp"`
+	Submission string `yaml:"submission" toml:"submission"` // STARTTLS.
+	SMTPS      string `yaml:"smtps" toml:"smtps"`           // implicit TLS.
+	HTTP       string `yaml:"http" toml:"http"`
+}
+
+type TLSConfig struct {
+	Cert    string `yaml:"cert" toml:"cert"`
+	Key     string `yaml:"key" toml:"key"`
+	Require bool   `yaml:"require" toml:"require"`
+}
+
+type TimeoutsConfig struct {
+	Session Duration `yaml:"session" toml:"session"` // to hand a mail over to the hooks.
+	Read    Duration `yaml:"read" toml:"read"`       // of SMTP commands. 0 never times out.
+	Write   Duration `yaml:"write" toml:"write"`     // of SMTP replies. 0 never times out.
+	Webhook Duration `yaml:"webhook" toml:"webhook"` // of a webhook request.
+	Lookup  Duration `yaml:"lookup" toml:"lookup"`   // of DNS lookups to verify senders.
+
+	// Shutdown is the time to drain SMTP sessions, HTTP requests
+	// and webhook deliveries in flight on SIGTERM or SIGINT.
+	Shutdown Duration `yaml:"shutdown" toml:"shutdown"`
+}
+
+// LimitsConfig limits SMTP sessions. 0 means unlimited.
+type LimitsConfig struct {
+	MaxMessageBytes int64 `yaml:"max_message_bytes" toml:"max_message_bytes"`
+	MaxRecipients   int   `yaml:"max_recipients" toml:"max_recipients"`
+	MaxLineLength   int   `yaml:"max_line_length" toml:"max_line_length"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level"`   // debug, info, warn or error.
+	Format string `yaml:"format" toml:"format"` // text or json.
+}
+
+// ForwardConfig forwards every mail to the recipients through the SMTP server.
+// Forwarding is disabled without recipients.
+type ForwardConfig struct {
+	To   []string `yaml:"to" toml:"to"`
+	Host string   `yaml:"host" toml:"host"`
+	Port int      `yaml:"port" toml:"port"`
+	User string   `yaml:"user" toml:"user"`
+	Pass string   `yaml:"pass" toml:"pass"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Database: DatabaseConfig{
+			DSN:         "db/sqlite3.db?_foreign_keys=on",
+			AutoMigrate: true,
+		},
+		Listen: ListenConfig{
+			SMTP:       "0.0.0.0:25",
+			Submission: "0.0.0.0:587",
+			SMTPS:      "0.0.0.0:465",
+			HTTP:       "0.0.0.0:8080",
+		},
+		Timeouts: TimeoutsConfig{
+			Session: Duration(time.Second * 5),
+			Webhook: Duration(time.Second * 10),
+			Lookup:  Duration(time.Second * 3),
+
+			Shutdown: Duration(time.Second * 30),
+		},
+		Limits: LimitsConfig{
+			MaxLineLength: 2000,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Forward: ForwardConfig{
+			Port: 587,
+		},
+	}
+}
+
+// readFile overrides the Config by the YAML or TOML file, chosen by the extension.
+// Unknown keys are errors to catch typos.
+func (c *Config) readFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("%s: the config file has to be .yaml, .yml or .toml", path)
+	}
+	return nil
+}
+
+// readEnv overrides the Config by the environment variables set.
+func (c *Config) readEnv() {
+	str := func(dst *string, key string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*dst = v
+		}
+	}
+	boolean := func(dst *bool, key string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*dst = v == "true"
+		}
+	}
+	str(&c.Domain, "DOMAIN")
+	str(&c.Hostname, "SMTP_DOMAIN")
+	str(&c.Secret, "SECRET")
+	boolean(&c.VerifySender, "VERIFY_SENDER")
+
+	str(&c.Database.DSN, "DATABASE_URL")
+	if v, ok := os.LookupEnv("AUTO_MIGRATE"); ok {
+		c.Database.AutoMigrate = v != "false"
+	}
+
+	str(&c.TLS.Cert, "TLS_CERT")
+	str(&c.TLS.Key, "TLS_KEY")
+	boolean(&c.TLS.Require, "REQUIRE_TLS")
+
+	str(&c.Forward.Host, "SMTP_HOST")
+	str(&c.Forward.User, "SMTP_USER")
+	str(&c.Forward.Pass, "SMTP_PASS")
+	if to := splitList(os.Getenv("FORWARD_TO")); len(to) > 0 {
+		c.Forward.To = to
+	}
+}
+
+// splitList splits the comma separated list, dropping empty items.
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// bindFlags defines the flags overriding the Config,
+// whose current values are the defaults of the flags.
+func (c *Config) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Domain, "domain", c.Domain, "the default domain of addresses")
+	fs.StringVar(&c.Hostname, "hostname", c.Hostname, "the name the SMTP server greets with")
+	fs.BoolVar(&c.VerifySender, "verify-sender", c.VerifySender, "check SPF, DKIM and DMARC of every mail")
+
+	fs.StringVar(&c.Database.DSN, "database-url", c.Database.DSN, "the path of a SQLite database or a postgres:// URL")
+	fs.BoolVar(&c.Database.AutoMigrate, "auto-migrate", c.Database.AutoMigrate, "apply migrations on start")
+
+	fs.StringVar(&c.Listen.SMTP, "smtp-addr", c.Listen.SMTP, "the address to listen on for SMTP")
+	fs.StringVar(&c.Listen.Submission, "submission-addr", c.Listen.Submission, "the address to listen on for SMTP with STARTTLS")
+	fs.StringVar(&c.Listen.SMTPS, "smtps-addr", c.Listen.SMTPS, "the address to listen on for SMTP with implicit TLS")
+	fs.StringVar(&c.Listen.HTTP, "http-addr", c.Listen.HTTP, "the address to listen on for the API")
+
+	fs.StringVar(&c.TLS.Cert, "tls-cert", c.TLS.Cert, "the PEM file of the certificate")
+	fs.StringVar(&c.TLS.Key, "tls-key", c.TLS.Key, "the PEM file of the private key")
+	fs.BoolVar(&c.TLS.Require, "require-tls", c.TLS.Require, "refuse MAIL FROM until the connection is secured")
+
+	fs.TextVar(&c.Timeouts.Session, "session-timeout", c.Timeouts.Session, "the timeout to hand a mail over")
+	fs.TextVar(&c.Timeouts.Read, "read-timeout", c.Timeouts.Read, "the timeout of SMTP commands")
+	fs.TextVar(&c.Timeouts.Write, "write-timeout", c.Timeouts.Write, "the timeout of SMTP replies")
+	fs.TextVar(&c.Timeouts.Webhook, "webhook-timeout", c.Timeouts.Webhook, "the timeout of a webhook request")
+	fs.TextVar(&c.Timeouts.Lookup, "lookup-timeout", c.Timeouts.Lookup, "the timeout of DNS lookups")
+	fs.TextVar(&c.Timeouts.Shutdown, "shutdown-timeout", c.Timeouts.Shutdown, "the time to drain in-flight work on shutdown")
+
+	fs.Int64Var(&c.Limits.MaxMessageBytes, "max-message-bytes", c.Limits.MaxMessageBytes, "the max size of a mail, 0 is unlimited")
+	fs.IntVar(&c.Limits.MaxRecipients, "max-recipients", c.Limits.MaxRecipients, "the max recipients of a mail, 0 is unlimited")
+	fs.IntVar(&c.Limits.MaxLineLength, "max-line-length", c.Limits.MaxLineLength, "the max length of a line, 0 is unlimited")
+
+	fs.StringVar(&c.Log.Level, "log-level", c.Log.Level, "debug, info, warn or error")
+	fs.StringVar(&c.Log.Format, "log-format", c.Log.Format, "text or json")
+
+	fs.StringVar(&c.Forward.Host, "forward-host", c.Forward.Host, "the SMTP server to forward mails through")
+	fs.IntVar(&c.Forward.Port, "forward-port", c.Forward.Port, "the port of the SMTP server to forward mails through")
+}
+
+// Validate returns every problem of the Config at once.
+func (c Config) Validate() error {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Database.DSN == "" {
+		fail("database.dsn is required")
+	}
+	listeners := map[string]string{
+		"listen.smtp":       c.Listen.SMTP,
+		"listen.submission": c.Listen.Submission,
+		"listen.smtps":      c.Listen.SMTPS,
+		"listen.http":       c.Listen.HTTP,
+	}
+	for key, addr := range listeners {
+		if addr == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			fail("%s: %s", key, err)
+		}
+	}
+	if c.Listen.HTTP == "" {
+		fail("listen.http is required")
+	}
+	if (c.TLS.Cert == "") != (c.TLS.Key == "") {
+		fail("tls.cert and tls.key have to be set together")
+	}
+	if c.TLS.Require && c.TLS.Cert == "" {
+		fail("tls.require needs tls.cert and tls.key")
+	}
+	timeouts := map[string]Duration{
+		"timeouts.session":  c.Timeouts.Session,
+		"timeouts.read":     c.Timeouts.Read,
+		"timeouts.write":    c.Timeouts.Write,
+		"timeouts.webhook":  c.Timeouts.Webhook,
+		"timeouts.lookup":   c.Timeouts.Lookup,
+		"timeouts.shutdown": c.Timeouts.Shutdown,
+	}
+	for key, d := range timeouts {
+		if d < 0 {
+			fail("%s must not be negative", key)
+		}
+	}
+	if c.Timeouts.Session == 0 {
+		fail("timeouts.session must be positive")
+	}
+	if c.Timeouts.Shutdown == 0 {
+		fail("timeouts.shutdown must be positive")
+	}
+	if c.Limits.MaxMessageBytes < 0 || c.Limits.MaxRecipients < 0 || c.Limits.MaxLineLength < 0 {
+		fail("limits must not be negative")
+	}
+	if _, err := c.Log.level(); err != nil {
+		fail("log.level: %s", err)
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		fail("log.format has to be text or json")
+	}
+	if len(c.Forward.To) > 0 {
+		if c.Forward.Host == "" {
+			fail("forward.host is required to forward mails")
+		}
+		if c.Forward.Port <= 0 || c.Forward.Port > 65535 {
+			fail("forward.port is out of range")
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return errors.New("invalid config:\n  " + strings.Join(problems, "\n  "))
+}
+
+func (l LogConfig) level() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(l.Level))
+	return level, err
+}
+
+// logger returns the Logger writing to `w` as configured.
+func (l LogConfig) logger(w io.Writer) *slog.Logger {
+	level, _ := l.level()
+	options := &slog.HandlerOptions{Level: level}
+	if l.Format == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// loadConfig returns the Config for the command line,
+// whether only to check it, and the args following the flags.
+func loadConfig(args []string) (*Config, bool, []string, error) {
+	// The flags are parsed twice, first to find the config file,
+	// then to override the Config loaded from it.
+	// Errors are reported by the second parse.
+	scratch := defaultConfig()
+	fs := newFlagSet(&scratch, io.Discard)
+	_ = fs.Parse(args)
+	path := fs.Lookup("config").Value.String()
+
+	c := defaultConfig()
+	if path != "" {
+		if err := c.readFile(path); err != nil {
+			return nil, false, nil, err
+		}
+	}
+	c.readEnv()
+
+	fs = newFlagSet(&c, os.Stderr)
+	if err := fs.Parse(args); err != nil {
+		return nil, false, nil, err
+	}
+	check := fs.Lookup("check-config").Value.String() == "true"
+	return &c, check, fs.Args(), nil
+}
+
+func newFlagSet(c *Config, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: serve [flags] [migrate up | down [steps] | version]")
+		fs.PrintDefaults()
+	}
+	fs.String("config", "", "the YAML or TOML config file")
+	fs.Bool("check-config", false, "validate the config and exit")
+	c.bindFlags(fs)
+	return fs
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_YAML(t *testing.T) {
+	path := writeConfig(t, "mtw.yaml", `
+domain: mail.com
+listen:
+  smtp: 127.0.0.1:2525
+timeouts:
+  session: 30s
+limits:
+  max_message_bytes: 1048576
+log:
+  format: json
+forward:
+  to: [alice@mail.com, bob@mail.com]
+  host: smtp.mail.com
+`)
+	c, check, args, err := loadConfig([]string{"--config", path, "migrate", "up"})
+
+	assert.Nil(t, err)
+	assert.False(t, check)
+	assert.Equal(t, []string{"migrate", "up"}, args)
+	assert.Equal(t, "mail.com", c.Domain)
+	assert.Equal(t, "127.0.0.1:2525", c.Listen.SMTP)
+	assert.Equal(t, "0.0.0.0:8080", c.Listen.HTTP)
+	assert.Equal(t, Duration(time.Second*30), c.Timeouts.Session)
+	assert.Equal(t, int64(1048576), c.Limits.MaxMessageBytes)
+	assert.Equal(t, "json", c.Log.Format)
+	assert.Equal(t, []string{"alice@mail.com", "bob@mail.com"}, c.Forward.To)
+	assert.Equal(t, 587, c.Forward.Port)
+	assert.Nil(t, c.Validate())
+}
+
+func TestLoadConfig_TOML(t *testing.T) {
+	path := writeConfig(t, "mtw.toml", `
+domain = "mail.com"
+
+[database]
+dsn = "postgres://localhost/mtw"
+
+[timeouts]
+webhook = "1m"
+`)
+	c, _, _, err := loadConfig([]string{"--config", path})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "postgres://localhost/mtw", c.Database.DSN)
+	assert.Equal(t, Duration(time.Minute), c.Timeouts.Webhook)
+	assert.True(t, c.Database.AutoMigrate)
+}
+
+func TestLoadConfig_UnknownKey(t *testing.T) {
+	path := writeConfig(t, "mtw.yaml", "listen:\n  smpt: 127.0.0.1:25\n")
+	_, _, _, err := loadConfig([]string{"--config", path})
+
+	assert.NotNil(t, err)
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	path := writeConfig(t, "mtw.yaml", "domain: file.com\nhostname: mx.file.com\nlog:\n  level: debug\n")
+	t.Setenv("DOMAIN", "env.com")
+	c, check, _, err := loadConfig([]string{"--config", path, "--log-level", "warn", "--session-timeout", "10s", "--check-config"})
+
+	assert.Nil(t, err)
+	assert.True(t, check)
+	assert.Equal(t, "env.com", c.Domain)
+	assert.Equal(t, "mx.file.com", c.Hostname)
+	assert.Equal(t, "warn", c.Log.Level)
+	assert.Equal(t, Duration(time.Second*10), c.Timeouts.Session)
+}
+
+func TestLoadConfig_ForwardTo(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"only commas", " , ,", nil},
+		{"list", "a@mail.com, ,b@mail.com,", []string{"a@mail.com", "b@mail.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FORWARD_TO", tt.env)
+			t.Setenv("SMTP_HOST", "smtp.mail.com")
+			c, _, _, err := loadConfig(nil)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, c.Forward.To)
+			assert.NoError(t, c.Validate())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := defaultConfig()
+	c.Listen.SMTP = "25"
+	c.TLS.Require = true
+	c.Log.Level = "verbose"
+	c.Forward.To = []string{"alice@mail.com"}
+
+	err := c.Validate()
+	assert.Equal(t, `invalid config:
+  forward.host is required to forward mails
+  listen.smtp: address 25: missing port in address
+  log.level: slog: level string "verbose": unknown name
+  tls.require needs tls.cert and tls.key`, err.Error())
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+const migrateUsage = "usage: serve migrate up | down [steps] | version"
+
+// migrate runs `serve migrate` with the args following it, and returns the exit code.
+//
+//   - up applies every migration not applied yet.
+//   - down reverts the last migration, or the last `steps` ones.
+//   - version prints the version of the schema and the latest one.
+func migrate(db *sql.DB, args []string, logger *slog.Logger) int {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		return exitUsage
+	}
+	switch args[0] {
+	case "up":
+		if err := database.Migrate(db); err != nil {
+			logger.Error("failed to migrate", "inner", err.Error())
+			return exitFailure
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				fmt.Println(migrateUsage)
+				return exitUsage
+			}
+			steps = n
+		}
+		if err := database.Rollback(db, steps); err != nil {
+			logger.Error("failed to roll back", "inner", err.Error())
+			return exitFailure
+		}
+	case "version":
+	default:
+		fmt.Println(migrateUsage)
+		return exitUsage
+	}
+
+	v, err := database.SchemaVersion(db)
+	if err != nil {
+		logger.Error("failed to get the version", "inner", err.Error())
+		return exitFailure
+	}
+	fmt.Printf("version %d, latest %d, dirty %t\n", v.Current, v.Latest, v.Dirty)
+	return 0
+}
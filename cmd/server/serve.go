@@ -2,9 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
+	"net"
+	nethttp "net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	ns "net/smtp"
@@ -12,103 +22,254 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/zen-en-tonal/mtw/database"
 	"github.com/zen-en-tonal/mtw/database/address"
-	"github.com/zen-en-tonal/mtw/database/webhook"
+	"github.com/zen-en-tonal/mtw/database/credential"
+	"github.com/zen-en-tonal/mtw/database/delivery"
+	dbdomain "github.com/zen-en-tonal/mtw/database/domain"
+	"github.com/zen-en-tonal/mtw/database/outbox"
+	"github.com/zen-en-tonal/mtw/database/rule"
+	dbtoken "github.com/zen-en-tonal/mtw/database/token"
 	"github.com/zen-en-tonal/mtw/forward"
 	"github.com/zen-en-tonal/mtw/http"
+	"github.com/zen-en-tonal/mtw/queue"
 	"github.com/zen-en-tonal/mtw/session"
 	"github.com/zen-en-tonal/mtw/smtp"
+	"github.com/zen-en-tonal/mtw/spam"
+	"github.com/zen-en-tonal/mtw/token"
 	wh "github.com/zen-en-tonal/mtw/webhook"
 )
 
-var (
-	domain string = ""
-
-	smtpUser  string = ""
-	smtpPass  string = ""
-	smtpHost  string = ""
-	forwardTo string = ""
-
-	dbconn string = "db/sqlite3.db"
-
-	secret string = ""
-)
-
-func init() {
-	domain, _ = os.LookupEnv("DOMAIN")
-	secret, _ = os.LookupEnv("SECRET")
-
-	smtpUser, _ = os.LookupEnv("SMTP_USER")
-	smtpPass, _ = os.LookupEnv("SMTP_PASS")
-	smtpHost, _ = os.LookupEnv("SMTP_HOST")
-	forwardTo, _ = os.LookupEnv("FORWARD_TO")
+func main() {
+	os.Exit(run())
 }
 
-func main() {
-	logger := slog.Default()
+// run serves until a signal or a failure, and returns the exit code.
+func run() int {
+	config, check, args, err := loadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if err := config.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	if check {
+		fmt.Println("config ok")
+		return exitOK
+	}
+	logger := config.Log.logger(os.Stderr)
+	slog.SetDefault(logger)
 
-	db, err := sql.Open(database.Driver, dbconn)
+	db, err := database.Open(config.Database.DSN)
 	if err != nil {
 		logger.Error("failed to connect to db", "inner", err.Error())
-		return
+		return exitFailure
 	}
-	if err := database.Migrate(db, "ql"); err != nil {
-		logger.Warn("migration failure", "inner", err.Error())
+	defer db.Close()
+	if len(args) > 0 && args[0] == "migrate" {
+		return migrate(db, args[1:], logger)
+	}
+	if config.Database.AutoMigrate {
+		if err := database.Migrate(db); err != nil {
+			logger.Error("migration failure", "inner", err.Error())
+			return exitFailure
+		}
+	}
+	if err := database.CheckSchema(db); err != nil {
+		logger.Error("refused to start, run `serve migrate up` first", "inner", err.Error())
+		return exitFailure
+	}
+
+	if config.Secret == "" {
+		if err := bootstrapToken(db, os.Stderr, logger); err != nil {
+			logger.Error("failed to issue the first token", "inner", err.Error())
+			return exitFailure
+		}
 	}
 
-	if secret == "" {
-		logger.Error("secret must be set")
-		return
+	if config.Domain != "" {
+		if _, err := dbdomain.Create(db).One(config.Domain); err != nil {
+			logger.Error("failed to register the domain", "domain", config.Domain, "inner", err.Error())
+			return exitFailure
+		}
+	}
+	hostname := config.Hostname
+	if hostname == "" {
+		hostname = config.Domain
 	}
 
 	hooks := []session.Hook{
-		session.AsHook(webhook.NewFind(db, wh.WithLogger(logger))),
+		outbox.NewEnqueue(db),
 	}
-	if forwardTo != "" {
-		auth := ns.PlainAuth("", smtpUser, smtpPass, smtpHost)
-		hooks = append(hooks, forward.NewSmtp(smtpHost, auth, forwardTo))
+	if fw := config.Forward; len(fw.To) > 0 {
+		auth := ns.PlainAuth("", fw.User, fw.Pass, fw.Host)
+		addr := net.JoinHostPort(fw.Host, strconv.Itoa(fw.Port))
+		hooks = append(hooks, forward.NewSmtp(addr, auth, fw.To...))
 	}
 
-	smtp := smtp.New(
-		smtp.WithSessionOptions(
-			session.WithFilters(address.Find(db)),
-			session.WithHooksSome(hooks...),
-			session.WithLogger(logger),
-			session.WithTimeout(time.Second*5),
-		),
+	filters := []session.Filter{
+		address.Find(db),
+	}
+	sessionOptions := []session.Option{
+		session.WithRcptPolicies(dbdomain.Find(db), address.Find(db)),
+		session.WithSenderPolicies(rule.Find(db)),
+		session.WithAuthenticator(credential.Find(db)),
+		session.WithHooksSome(hooks...),
+		session.WithLogger(logger),
+		session.WithTimeout(time.Duration(config.Timeouts.Session)),
+	}
+	if config.VerifySender {
+		auth := spam.AuthFilter(spam.WithLookupTimeout(time.Duration(config.Timeouts.Lookup)))
+		filters = append(filters, auth)
+		sessionOptions = append(sessionOptions, session.WithVerifier(auth))
+	}
+	sessionOptions = append(sessionOptions, session.WithFilters(filters...))
+
+	smtpOptions := []smtp.Option{
+		smtp.WithSessionOptions(sessionOptions...),
 		smtp.WithLogger(logger),
-	)
-	smtp.Addr = "0.0.0.0:25"
-	smtp.Domain = domain
-	smtp.AllowInsecureAuth = false
+	}
+	if config.TLS.Cert != "" {
+		certs, err := smtp.NewCertReloader(config.TLS.Cert, config.TLS.Key)
+		if err != nil {
+			logger.Error("failed to load the certificate", "inner", err.Error())
+			return exitFailure
+		}
+		smtpOptions = append(smtpOptions, smtp.WithTLS(certs.TLSConfig()))
+	}
+	if config.TLS.Require {
+		smtpOptions = append(smtpOptions, smtp.WithRequireTLS())
+	}
+
+	server := smtp.New(smtpOptions...)
+	server.Domain = hostname
+	server.AllowInsecureAuth = false
+	server.ReadTimeout = time.Duration(config.Timeouts.Read)
+	server.WriteTimeout = time.Duration(config.Timeouts.Write)
+	server.MaxMessageBytes = config.Limits.MaxMessageBytes
+	server.MaxRecipients = config.Limits.MaxRecipients
+	server.MaxLineLength = config.Limits.MaxLineLength
 
 	rest := gin.New()
-	rest.Use(authMiddle)
-	http.SetRoutes(rest, db, domain, logger)
+	http.SetRoutes(rest, db, config.Domain, config.Secret, logger)
+
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	worker := queue.New(
+		outbox.NewQueue(
+			db,
+			wh.WithLogger(logger),
+			wh.WithRecorder(delivery.NewRecord(db)),
+			wh.WithTimeout(time.Duration(config.Timeouts.Webhook)),
+		),
+		queue.WithLogger(logger),
+	)
+	workerDone := make(chan struct{})
+	go func() {
+		worker.Run(workerCtx)
+		close(workerDone)
+	}()
 
-	go func(ctx *context.Context) {
-		logger.Info("Listening and serving SMTP on 0.0.0.0:25")
-		if err := smtp.ListenAndServe(); err != nil {
-			logger.Error("smtp", "inner", err.Error())
+	// The sessions still open at the shutdown deadline are cut off through the tracker.
+	sessions := smtp.NewTracker()
+	listenTCP := func(addr string) (net.Listener, error) {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return sessions.Listener(l), nil
+	}
+	listeners := map[string]func() (net.Listener, error){}
+	if addr := config.Listen.SMTP; addr != "" {
+		listeners[addr] = func() (net.Listener, error) { return listenTCP(addr) }
+	}
+	if server.TLSConfig != nil {
+		if addr := config.Listen.Submission; addr != "" {
+			listeners[addr] = func() (net.Listener, error) { return listenTCP(addr) }
+		}
+		if addr := config.Listen.SMTPS; addr != "" {
+			listeners[addr] = func() (net.Listener, error) {
+				l, err := listenTCP(addr)
+				if err != nil {
+					return nil, err
+				}
+				return tls.NewListener(l, server.TLSConfig), nil
+			}
 		}
-		cancel()
-	}(&ctx)
-	go func(ctx *context.Context) {
-		logger.Info("Listening and serving HTTP on 0.0.0.0:8080")
-		if err := rest.Run("0.0.0.0:8080"); err != nil {
-			logger.Error("http", "inner", err.Error())
+	}
+	failures := make(chan error, len(listeners)+1)
+	for addr, listen := range listeners {
+		go func() {
+			logger.Info("Listening and serving SMTP on " + addr)
+			l, err := listen()
+			if err == nil {
+				err = server.Serve(l)
+			}
+			if err != nil {
+				failures <- fmt.Errorf("smtp %s: %w", addr, err)
+			}
+		}()
+	}
+	api := &nethttp.Server{Addr: config.Listen.HTTP, Handler: rest}
+	go func() {
+		logger.Info("Listening and serving HTTP on " + config.Listen.HTTP)
+		if err := api.ListenAndServe(); !errors.Is(err, nethttp.ErrServerClosed) {
+			failures <- fmt.Errorf("http: %w", err)
 		}
-		cancel()
-	}(&ctx)
-	<-ctx.Done()
+	}()
+
+	failed := false
+	select {
+	case <-signals.Done():
+		logger.Info("shutting down", "timeout", time.Duration(config.Timeouts.Shutdown).String())
+	case err := <-failures:
+		logger.Error("shutting down after a failure", "inner", err.Error())
+		failed = true
+	}
+	// A second signal kills the server at once.
+	stop()
+
+	deadline, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeouts.Shutdown))
+	defer cancel()
+	// A session finishing late still persists its mail in the outbox
+	// for the next run, so the worker does not have to outlive the servers.
+	// Deliveries claimed but not sent yet are retried once their lease expires.
+	err = drain(deadline, logger,
+		drainer{name: "smtp", shutdown: server.Shutdown, close: sessions.Close},
+		drainer{name: "http", shutdown: api.Shutdown, close: api.Close},
+		drainer{name: "webhook", shutdown: func(ctx context.Context) error {
+			stopWorker()
+			select {
+			case <-workerDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}},
+	)
+	if err != nil {
+		logger.Error("shutdown", "inner", err.Error())
+	}
+	return exitCode(failed, err)
 }
 
-func authMiddle(c *gin.Context) {
-	if c.GetHeader("Authorization") != "Bearer "+secret {
-		c.Status(401)
-		c.Abort()
-	} else {
-		c.Next()
+// bootstrapToken issues an admin token if none exists, and prints its secret only once to w.
+// The secret is kept out of the logger, whose records may be shipped elsewhere.
+func bootstrapToken(db *sql.DB, w io.Writer, logger *slog.Logger) error {
+	exists, err := dbtoken.Find(db).Exists()
+	if err != nil || exists {
+		return err
+	}
+	_, secret, err := dbtoken.Create(db).One("bootstrap", "admin", []string{string(token.Admin)}, nil)
+	if err != nil {
+		return err
 	}
+	logger.Warn("issued the first admin token since no secret is set, see stderr")
+	_, err = fmt.Fprintf(w, "mtw: the first admin token is %s, keep it since it is not shown again\n", secret)
+	return err
 }
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+func TestBootstrapToken(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "mtw.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	assert.NoError(t, bootstrapToken(db, &out, logger))
+	assert.Contains(t, out.String(), "mtw_")
+	assert.NotContains(t, logs.String(), "mtw_")
+	assert.Equal(t, 1, strings.Count(logs.String(), "\n"))
+
+	// The token is issued only once.
+	out.Reset()
+	assert.NoError(t, bootstrapToken(db, &out, logger))
+	assert.Empty(t, out.String())
+}
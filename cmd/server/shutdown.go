@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Exit codes of the server.
+const (
+	exitOK      = 0 // stopped by a signal and drained everything in flight.
+	exitFailure = 1 // failed to start, or a server failed while running.
+	exitUsage   = 2 // invalid flags.
+	exitCutOff  = 3 // stopped by a signal, but cut off work in flight at the deadline.
+)
+
+var errCutOff = errors.New("cut off at the shutdown deadline")
+
+// drainer is something to stop on shutdown.
+type drainer struct {
+	name string
+	// shutdown stops taking new work and waits for the work in flight until ctx is done.
+	shutdown func(ctx context.Context) error
+	// close cuts off the work shutdown is still waiting for. It is optional.
+	close func() error
+}
+
+// drain shuts every drainer down at once and waits for them until ctx is done.
+// A drainer still busy at the deadline is closed.
+//
+// # Errors
+//   - errCutOff if a drainer did not finish before the deadline.
+func drain(ctx context.Context, logger *slog.Logger, drainers ...drainer) error {
+	errs := make([]error, len(drainers))
+	var wg sync.WaitGroup
+	for i, d := range drainers {
+		wg.Add(1)
+		go func(i int, d drainer) {
+			defer wg.Done()
+			err := d.shutdown(ctx)
+			if err == nil {
+				logger.Info("drained", "name", d.name)
+				return
+			}
+			if ctx.Err() == nil {
+				errs[i] = fmt.Errorf("%s: %w", d.name, err)
+				return
+			}
+			errs[i] = fmt.Errorf("%s: %w", d.name, errCutOff)
+			if d.close != nil {
+				if err := d.close(); err != nil {
+					logger.Error("failed to close", "name", d.name, "inner", err.Error())
+				}
+			}
+		}(i, d)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// exitCode returns the exit code after a shutdown.
+func exitCode(failed bool, err error) int {
+	switch {
+	case failed:
+		return exitFailure
+	case errors.Is(err, errCutOff):
+		return exitCutOff
+	case err != nil:
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestDrain(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	closed := false
+	err := drain(ctx, discard,
+		drainer{name: "quick", shutdown: func(context.Context) error { return nil }, close: func() error {
+			closed = true
+			return nil
+		}},
+	)
+	assert.Nil(t, err)
+	assert.False(t, closed)
+	assert.Equal(t, exitOK, exitCode(false, err))
+}
+
+func TestDrain_CutOff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	closed := false
+	err := drain(ctx, discard,
+		drainer{name: "quick", shutdown: func(context.Context) error { return nil }},
+		drainer{name: "slow", shutdown: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, close: func() error {
+			closed = true
+			return nil
+		}},
+	)
+	assert.ErrorIs(t, err, errCutOff)
+	assert.ErrorContains(t, err, "slow")
+	assert.NotContains(t, err.Error(), "quick")
+	assert.True(t, closed)
+	assert.Equal(t, exitCutOff, exitCode(false, err))
+}
+
+func TestDrain_Failure(t *testing.T) {
+	failure := errors.New("failure")
+	err := drain(context.Background(), discard,
+		drainer{name: "broken", shutdown: func(context.Context) error { return failure }},
+	)
+	assert.ErrorIs(t, err, failure)
+	assert.NotErrorIs(t, err, errCutOff)
+	assert.Equal(t, exitFailure, exitCode(false, err))
+	assert.Equal(t, exitFailure, exitCode(true, nil))
+}
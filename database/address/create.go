@@ -2,21 +2,38 @@ package address
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 
+	"github.com/zen-en-tonal/mtw/database/domain"
 	"github.com/zen-en-tonal/mtw/session"
 )
 
+var ErrUnknownDomain = errors.New("domain is not registered")
+
 type CreateHandle struct {
 	addressRepository
-	domain string
+	domain  string
+	domains domain.FindHandle
+	owner   string
+}
+
+// Create returns a handle to create and persist a Address on the domain.
+// The domain must be registered.
+func Create(db *sql.DB, domainName string) CreateHandle {
+	return CreateHandle{newRepository(db), domainName, domain.Find(db), ""}
 }
 
-// Create returns a handle to create and persist a Address.
-func Create(db *sql.DB, domain string) CreateHandle {
-	return CreateHandle{newRepository(db), domain}
+// OwnedBy returns a handle to create Addresses owned by the owner.
+func (c CreateHandle) OwnedBy(owner string) CreateHandle {
+	c.owner = owner
+	return c
 }
 
 // WithUser persists an address with the specified username.
+//
+// # Errors
+//   - ErrUnknownDomain if the domain is not registered.
 func (c CreateHandle) WithUser(user string) (*session.Address, error) {
 	addr, err := session.NewAddr(user, c.domain)
 	if err != nil {
@@ -26,6 +43,9 @@ func (c CreateHandle) WithUser(user string) (*session.Address, error) {
 }
 
 // WithRandom persists an address with the randomized username by uuid.
+//
+// # Errors
+//   - ErrUnknownDomain if the domain is not registered.
 func (c CreateHandle) WithRandom() (*session.Address, error) {
 	addr, err := session.RandomAddr(c.domain)
 	if err != nil {
@@ -35,8 +55,12 @@ func (c CreateHandle) WithRandom() (*session.Address, error) {
 }
 
 func (c CreateHandle) create(addr session.Address) (*session.Address, error) {
+	if !c.domains.Exists(addr.Domain()) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDomain, addr.Domain())
+	}
 	table := addressTable{
 		Address: addr.String(),
+		Owner:   c.owner,
 	}
 	if err := c.insert(table); err != nil {
 		return nil, err
@@ -0,0 +1,24 @@
+package address
+
+import (
+	"database/sql"
+
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+type DeleteHandle struct {
+	addressRepository
+}
+
+// Delete returns a handle to delete Addresses.
+func Delete(db *sql.DB) DeleteHandle {
+	return DeleteHandle{newRepository(db)}
+}
+
+// One deletes the Address and unregisters every Webhook from it.
+//
+// # Errors
+//   - If no Address found.
+func (d DeleteHandle) One(addr session.Address) error {
+	return d.delete(addr.String())
+}
@@ -2,10 +2,11 @@ package address
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
+	"github.com/zen-en-tonal/mtw/database"
 	"github.com/zen-en-tonal/mtw/session"
-	"github.com/zen-en-tonal/mtw/sync"
 )
 
 type FindHandle struct {
@@ -34,6 +35,35 @@ func (f FindHandle) All() (*[]session.Address, error) {
 	return &addrs, nil
 }
 
+// OwnedBy returns an array of Address owned by the owner.
+func (f FindHandle) OwnedBy(owner string) (*[]session.Address, error) {
+	tables, err := f.allOwnedBy(owner)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]session.Address, len(*tables))
+	for i, table := range *tables {
+		addr, err := table.into()
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = *addr
+	}
+	return &addrs, nil
+}
+
+// Owner returns the owner of the Address.
+//
+// # Errors
+//   - If the Address does not exist.
+func (f FindHandle) Owner(addr session.Address) (string, error) {
+	table, err := f.findOne(addr.String())
+	if err != nil {
+		return "", err
+	}
+	return table.Owner, nil
+}
+
 // Exists returns the addr exists in the DB or not.
 func (f FindHandle) Exists(addr session.Address) bool {
 	if _, err := f.findOne(addr.String()); err != nil {
@@ -42,27 +72,36 @@ func (f FindHandle) Exists(addr session.Address) bool {
 	return true
 }
 
+// AcceptRcpt refuses recipients that are not registered.
+func (f FindHandle) AcceptRcpt(rcpt session.Address) error {
+	_, err := f.findOne(rcpt.String())
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf(
+			"addr %s is not found: %w",
+			rcpt.String(),
+			session.ErrUnknownUser,
+		)
+	}
+	return err
+}
+
+// Validate refuses a Transaction whose recipient is not registered.
+// The recipient is taken from the envelope since the `To` header
+// may list other addresses or none at all (e.g. Bcc).
 func (f FindHandle) Validate(t session.Transaction) error {
-	validate := func(selector func(t session.Transaction) string) func(t session.Transaction) error {
-		return func(t session.Transaction) error {
-			maybeAddr := selector(t)
-			addr, err := session.ParseAddr(maybeAddr)
-			if err != nil {
-				return err
-			}
-			if !f.Exists(*addr) {
-				return fmt.Errorf(
-					"addr %s is not found: %w",
-					addr.String(),
-					session.ErrValidation,
-				)
-			}
-			return nil
+	addr, err := session.ParseAddr(t.RcptAddress())
+	if err != nil {
+		return fmt.Errorf("%w: %w", session.ErrValidation, err)
+	}
+	if _, err := f.findOne(addr.String()); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return fmt.Errorf(
+				"addr %s is not found: %w",
+				addr.String(),
+				session.ErrValidation,
+			)
 		}
+		return err
 	}
-	return sync.TryAll(
-		t,
-		validate(func(t session.Transaction) string { return t.RcptAddress() }),
-		validate(func(t session.Transaction) string { return t.To() }),
-	)
+	return nil
 }
@@ -0,0 +1,24 @@
+package address
+
+import (
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+// postgresRepository stores Addresses in Postgres,
+// whose schema deletes the links and the sender rules of an address by cascade.
+type postgresRepository struct {
+	sqliteRepository
+}
+
+func (r postgresRepository) delete(addr string) error {
+	res, err := r.conn.Exec(`DELETE FROM addresses WHERE address = $1`, addr)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
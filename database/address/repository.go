@@ -3,44 +3,23 @@ package address
 import (
 	"database/sql"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/zen-en-tonal/mtw/database"
 )
 
-type addressRepository struct {
-	conn *sqlx.DB
+// addressRepository stores Addresses. Each database.Dialect has its own.
+type addressRepository interface {
+	insert(addr addressTable) error
+	all() (*[]addressTable, error)
+	allOwnedBy(owner string) (*[]addressTable, error)
+	findOne(addr string) (*addressTable, error)
+	// delete removes the address with its links to webhooks and its sender rules.
+	delete(addr string) error
 }
 
 func newRepository(db *sql.DB) addressRepository {
-	return addressRepository{sqlx.NewDb(db, database.Driver)}
-}
-
-func (r addressRepository) insert(addr addressTable) error {
-	_, err := r.conn.Exec(
-		`INSERT INTO addresses (address) VALUES ($1)`,
-		addr.Address,
-	)
-	return err
-}
-
-func (r addressRepository) all() (*[]addressTable, error) {
-	var tables []addressTable
-	if err := r.conn.Select(&tables, `SELECT address FROM addresses`); err != nil {
-		return nil, err
-	}
-	return &tables, nil
-}
-
-func (r addressRepository) findOne(addr string) (*addressTable, error) {
-	var tables []addressTable
-	if err := r.conn.Select(
-		&tables,
-		`SELECT address FROM addresses WHERE address = $1`,
-		addr); err != nil {
-		return nil, err
-	}
-	if len(tables) == 0 {
-		return nil, database.ErrNotFound
+	conn := database.Conn(db)
+	if database.DialectOfDB(db) == database.Postgres {
+		return postgresRepository{sqliteRepository{conn}}
 	}
-	return &tables[0], nil
+	return sqliteRepository{conn}
 }
@@ -0,0 +1,80 @@
+package address
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+// sqliteRepository stores Addresses in SQLite.
+// Its queries are portable, and are shared with postgresRepository.
+type sqliteRepository struct {
+	conn *sqlx.DB
+}
+
+func (r sqliteRepository) insert(addr addressTable) error {
+	_, err := r.conn.Exec(
+		`INSERT INTO addresses (address, owner) VALUES ($1, $2)`,
+		addr.Address,
+		addr.Owner,
+	)
+	return err
+}
+
+func (r sqliteRepository) all() (*[]addressTable, error) {
+	var tables []addressTable
+	if err := r.conn.Select(&tables, `SELECT address, owner FROM addresses`); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r sqliteRepository) allOwnedBy(owner string) (*[]addressTable, error) {
+	var tables []addressTable
+	if err := r.conn.Select(
+		&tables,
+		`SELECT address, owner FROM addresses WHERE owner = $1`,
+		owner); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r sqliteRepository) findOne(addr string) (*addressTable, error) {
+	var tables []addressTable
+	if err := r.conn.Select(
+		&tables,
+		`SELECT address, owner FROM addresses WHERE address = $1`,
+		addr); err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, database.ErrNotFound
+	}
+	return &tables[0], nil
+}
+
+// delete removes the address with its links to webhooks and its sender rules.
+func (r sqliteRepository) delete(addr string) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM addresses_webhooks WHERE address = $1`, addr); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM sender_rules WHERE address = $1`, addr); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM addresses WHERE address = $1`, addr)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return database.ErrNotFound
+	}
+	return tx.Commit()
+}
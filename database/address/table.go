@@ -6,6 +6,7 @@ import (
 
 type addressTable struct {
 	Address string `db:"address"`
+	Owner   string `db:"owner"`
 }
 
 // into converts a webhookTable into a Webhook.
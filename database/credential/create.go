@@ -0,0 +1,63 @@
+package credential
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxPasswordBytes is the longest password bcrypt takes.
+const MaxPasswordBytes = 72
+
+var (
+	ErrEmptyUsername   = errors.New("username must not be empty")
+	ErrPasswordTooLong = errors.New("password must be at most 72 bytes")
+)
+
+type CreateHandle struct {
+	credentialRepository
+}
+
+// Create returns a handle to register credentials for SMTP AUTH.
+func Create(db *sql.DB) CreateHandle {
+	return CreateHandle{newRepository(db)}
+}
+
+// One registers the user with the password, or with a random one if empty.
+// Registering the user again changes the password.
+// Returns the password, which is never stored in plain.
+//
+// # Errors
+//   - ErrEmptyUsername if the username is empty.
+//   - ErrPasswordTooLong if the password is longer than MaxPasswordBytes.
+func (c CreateHandle) One(username string, password string) (string, error) {
+	if username == "" {
+		return "", ErrEmptyUsername
+	}
+	if len(password) > MaxPasswordBytes {
+		return "", ErrPasswordTooLong
+	}
+	if password == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		password = hex.EncodeToString(b)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	if err := c.upsert(credentialTable{
+		Username:     username,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now().UTC(),
+	}); err != nil {
+		return "", err
+	}
+	return password, nil
+}
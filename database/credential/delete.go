@@ -0,0 +1,20 @@
+package credential
+
+import "database/sql"
+
+type DeleteHandle struct {
+	credentialRepository
+}
+
+// Delete returns a handle to delete credentials.
+func Delete(db *sql.DB) DeleteHandle {
+	return DeleteHandle{newRepository(db)}
+}
+
+// One deletes the user.
+//
+// # Errors
+//   - If no user found.
+func (d DeleteHandle) One(username string) error {
+	return d.delete(username)
+}
@@ -0,0 +1,49 @@
+package credential
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/session"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type FindHandle struct {
+	credentialRepository
+}
+
+// Find returns a handle to get credentials.
+func Find(db *sql.DB) FindHandle {
+	return FindHandle{newRepository(db)}
+}
+
+// All returns an array of registered usernames.
+func (f FindHandle) All() (*[]string, error) {
+	tables, err := f.all()
+	if err != nil {
+		return nil, err
+	}
+	users := make([]string, len(*tables))
+	for i, table := range *tables {
+		users[i] = table.Username
+	}
+	return &users, nil
+}
+
+// Authenticate returns an error wrapping session.ErrAuthFailed
+// if the user is not registered or the password does not match.
+func (f FindHandle) Authenticate(username string, password string) error {
+	table, err := f.findOne(username)
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("user %s is not found: %w", username, session.ErrAuthFailed)
+	}
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(table.PasswordHash), []byte(password)); err != nil {
+		return fmt.Errorf("password of %s is mismatched: %w", username, session.ErrAuthFailed)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package credential
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+type credentialRepository struct {
+	conn *sqlx.DB
+}
+
+func newRepository(db *sql.DB) credentialRepository {
+	return credentialRepository{database.Conn(db)}
+}
+
+// upsert replaces the password of the user if exists.
+func (r credentialRepository) upsert(table credentialTable) error {
+	_, err := r.conn.Exec(`
+		INSERT INTO credentials (username, password_hash, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (username) DO UPDATE SET password_hash = excluded.password_hash
+		`,
+		table.Username,
+		table.PasswordHash,
+		table.CreatedAt,
+	)
+	return err
+}
+
+func (r credentialRepository) all() (*[]credentialTable, error) {
+	var tables []credentialTable
+	if err := r.conn.Select(&tables, `SELECT * FROM credentials ORDER BY username`); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r credentialRepository) findOne(username string) (*credentialTable, error) {
+	var tables []credentialTable
+	if err := r.conn.Select(
+		&tables,
+		`SELECT * FROM credentials WHERE username = $1`,
+		username); err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, database.ErrNotFound
+	}
+	return &tables[0], nil
+}
+
+func (r credentialRepository) delete(username string) error {
+	res, err := r.conn.Exec(`DELETE FROM credentials WHERE username = $1`, username)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
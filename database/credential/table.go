@@ -0,0 +1,9 @@
+package credential
+
+import "time"
+
+type credentialTable struct {
+	Username     string    `db:"username"`
+	PasswordHash string    `db:"password_hash"`
+	CreatedAt    time.Time `db:"created_at"`
+}
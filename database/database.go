@@ -2,43 +2,46 @@ package database
 
 import (
 	"database/sql"
+	"strings"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/sqlite"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-const Driver = "sqlite3"
+// Dialect is the kind of the database. It is also the name of its driver.
+type Dialect string
 
-func Migrate(db *sql.DB, dbname string) error {
-	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
-	if err != nil {
-		return err
-	}
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		dbname,
-		driver,
-	)
-	if err != nil {
-		return err
+const (
+	SQLite   Dialect = "sqlite3"
+	Postgres Dialect = "postgres"
+)
+
+// DialectOf returns the Dialect of the DSN.
+// A DSN starting with `postgres://` or `postgresql://` is of Postgres,
+// and anything else is the path of a SQLite database.
+func DialectOf(dsn string) Dialect {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return Postgres
 	}
-	return m.Up()
+	return SQLite
 }
 
-func Drop(db *sql.DB, dbname string) error {
-	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
-	if err != nil {
-		return err
-	}
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		dbname,
-		driver,
-	)
-	if err != nil {
-		return err
+// Open opens the database of the DSN with the driver of its Dialect.
+func Open(dsn string) (*sql.DB, error) {
+	return sql.Open(string(DialectOf(dsn)), dsn)
+}
+
+// DialectOfDB returns the Dialect of the driver the db is opened with.
+func DialectOfDB(db *sql.DB) Dialect {
+	switch db.Driver().(type) {
+	case *pq.Driver, pq.Driver:
+		return Postgres
 	}
-	return m.Drop()
+	return SQLite
+}
+
+// Conn wraps the db for the repositories.
+func Conn(db *sql.DB) *sqlx.DB {
+	return sqlx.NewDb(db, string(DialectOfDB(db)))
 }
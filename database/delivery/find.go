@@ -0,0 +1,69 @@
+package delivery
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+const (
+	DefaultLimit int = 50
+	MaxLimit     int = 500
+)
+
+// Query narrows down Deliveries.
+// Zero values are ignored.
+type Query struct {
+	WebhookID     *uuid.UUID
+	TransactionID *uuid.UUID
+	Address       string
+	Failed        *bool
+	Since         time.Time
+	Until         time.Time
+	Limit         int
+	Offset        int
+}
+
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return q.Limit
+}
+
+type Find struct{ deliveryRepository }
+
+// NewFind returns a handle to get Deliveries.
+func NewFind(db *sql.DB) Find {
+	return Find{newRepository(db)}
+}
+
+// ByWebhook returns Deliveries of the Webhook, newest first.
+func (f Find) ByWebhook(id webhook.WebhookID, q Query) (*[]webhook.Delivery, error) {
+	whid := uuid.UUID(id)
+	q.WebhookID = &whid
+	return f.query(q)
+}
+
+// ByTransaction returns Deliveries of the Transaction, newest first.
+func (f Find) ByTransaction(id uuid.UUID, q Query) (*[]webhook.Delivery, error) {
+	q.TransactionID = &id
+	return f.query(q)
+}
+
+func (f Find) query(q Query) (*[]webhook.Delivery, error) {
+	tables, err := f.find(q)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]webhook.Delivery, len(*tables))
+	for i, table := range *tables {
+		deliveries[i] = table.into()
+	}
+	return &deliveries, nil
+}
@@ -0,0 +1,19 @@
+package delivery
+
+import (
+	"database/sql"
+
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+type Record struct{ deliveryRepository }
+
+// NewRecord returns a handle to persist Deliveries.
+func NewRecord(db *sql.DB) Record {
+	return Record{newRepository(db)}
+}
+
+// Record persists a Delivery.
+func (r Record) Record(d webhook.Delivery) error {
+	return r.insert(fromDelivery(d))
+}
@@ -0,0 +1,95 @@
+package delivery
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+type deliveryRepository struct {
+	conn *sqlx.DB
+}
+
+func newRepository(db *sql.DB) deliveryRepository {
+	return deliveryRepository{database.Conn(db)}
+}
+
+func (r deliveryRepository) insert(table deliveryTable) error {
+	_, err := r.conn.Exec(`
+		INSERT INTO deliveries (
+			id
+		,	transaction_id
+		,	webhook_id
+		,	address
+		,	body_hash
+		,	status_code
+		,	response
+		,	latency
+		,	error
+		,	created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`,
+		table.ID,
+		table.TransactionID,
+		table.WebhookID,
+		table.Address,
+		table.BodyHash,
+		table.StatusCode,
+		table.Response,
+		table.Latency,
+		table.Error,
+		table.CreatedAt,
+	)
+	return err
+}
+
+// find returns deliveries matching the Query, newest first.
+func (r deliveryRepository) find(q Query) (*[]deliveryTable, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if q.WebhookID != nil {
+		where("webhook_id = $%d", *q.WebhookID)
+	}
+	if q.TransactionID != nil {
+		where("transaction_id = $%d", *q.TransactionID)
+	}
+	if q.Address != "" {
+		where("address = $%d", q.Address)
+	}
+	if q.Failed != nil {
+		if *q.Failed {
+			conds = append(conds, "error <> ''")
+		} else {
+			conds = append(conds, "error = ''")
+		}
+	}
+	if !q.Since.IsZero() {
+		where("created_at >= $%d", q.Since.UTC())
+	}
+	if !q.Until.IsZero() {
+		where("created_at < $%d", q.Until.UTC())
+	}
+
+	query := `SELECT deliveries.* FROM deliveries`
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, ` AND `)
+	}
+	args = append(args, q.limit(), q.Offset)
+	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	var tables []deliveryTable
+	if err := r.conn.Select(&tables, query, args...); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
@@ -0,0 +1,52 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+type deliveryTable struct {
+	ID            uuid.UUID `db:"id"`
+	TransactionID uuid.UUID `db:"transaction_id"`
+	WebhookID     uuid.UUID `db:"webhook_id"`
+	Address       string    `db:"address"`
+	BodyHash      string    `db:"body_hash"`
+	StatusCode    int       `db:"status_code"`
+	Response      string    `db:"response"`
+	Latency       int64     `db:"latency"` // milliseconds.
+	Error         string    `db:"error"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+func fromDelivery(d webhook.Delivery) deliveryTable {
+	return deliveryTable{
+		ID:            d.ID,
+		TransactionID: d.TransactionID,
+		WebhookID:     uuid.UUID(d.WebhookID),
+		Address:       d.Address,
+		BodyHash:      d.BodyHash,
+		StatusCode:    d.StatusCode,
+		Response:      d.Response,
+		Latency:       d.Latency.Milliseconds(),
+		Error:         d.Error,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+// into converts a deliveryTable into a Delivery.
+func (t deliveryTable) into() webhook.Delivery {
+	return webhook.Delivery{
+		ID:            t.ID,
+		TransactionID: t.TransactionID,
+		WebhookID:     webhook.WebhookID(t.WebhookID),
+		Address:       t.Address,
+		BodyHash:      t.BodyHash,
+		StatusCode:    t.StatusCode,
+		Response:      t.Response,
+		Latency:       time.Duration(t.Latency) * time.Millisecond,
+		Error:         t.Error,
+		CreatedAt:     t.CreatedAt,
+	}
+}
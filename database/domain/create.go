@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+type CreateHandle struct {
+	domainRepository
+}
+
+// Create returns a handle to register domains.
+func Create(db *sql.DB) CreateHandle {
+	return CreateHandle{newRepository(db)}
+}
+
+// One registers the domain. Registering it twice is not an error.
+//
+// # Errors
+//   - If the domain can not be a part of a mail address.
+func (c CreateHandle) One(domain string) (string, error) {
+	domain = strings.ToLower(domain)
+	if _, err := session.NewAddr("postmaster", domain); err != nil {
+		return "", err
+	}
+	if err := c.insert(domainTable{domain}); err != nil {
+		return "", err
+	}
+	return domain, nil
+}
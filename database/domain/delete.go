@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"database/sql"
+	"strings"
+)
+
+type DeleteHandle struct {
+	domainRepository
+}
+
+// Delete returns a handle to delete domains.
+func Delete(db *sql.DB) DeleteHandle {
+	return DeleteHandle{newRepository(db)}
+}
+
+// One deletes the domain.
+//
+// # Errors
+//   - If no domain found.
+//   - If an Address on the domain exists.
+func (d DeleteHandle) One(domain string) error {
+	return d.delete(strings.ToLower(domain))
+}
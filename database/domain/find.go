@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+type FindHandle struct {
+	domainRepository
+}
+
+// Find returns a handle to get domains.
+func Find(db *sql.DB) FindHandle {
+	return FindHandle{newRepository(db)}
+}
+
+// All returns an array of registered domains.
+func (f FindHandle) All() (*[]string, error) {
+	tables, err := f.all()
+	if err != nil {
+		return nil, err
+	}
+	domains := make([]string, len(*tables))
+	for i, table := range *tables {
+		domains[i] = table.Domain
+	}
+	return &domains, nil
+}
+
+// Exists returns the domain is registered or not.
+func (f FindHandle) Exists(domain string) bool {
+	if _, err := f.findOne(strings.ToLower(domain)); err != nil {
+		return false
+	}
+	return true
+}
+
+// AcceptRcpt refuses recipients on domains that are not registered.
+func (f FindHandle) AcceptRcpt(rcpt session.Address) error {
+	_, err := f.findOne(strings.ToLower(rcpt.Domain()))
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf(
+			"domain %s is not registered: %w",
+			rcpt.Domain(),
+			session.ErrUnknownDomain,
+		)
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+type domainRepository struct {
+	conn *sqlx.DB
+}
+
+func newRepository(db *sql.DB) domainRepository {
+	return domainRepository{database.Conn(db)}
+}
+
+func (r domainRepository) insert(table domainTable) error {
+	_, err := r.conn.Exec(`
+		INSERT INTO domains (domain) VALUES ($1)
+		ON CONFLICT (domain) DO NOTHING
+		`,
+		table.Domain,
+	)
+	return err
+}
+
+func (r domainRepository) all() (*[]domainTable, error) {
+	var tables []domainTable
+	if err := r.conn.Select(&tables, `SELECT domain FROM domains ORDER BY domain`); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r domainRepository) findOne(domain string) (*domainTable, error) {
+	var tables []domainTable
+	if err := r.conn.Select(
+		&tables,
+		`SELECT domain FROM domains WHERE domain = $1`,
+		domain); err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, database.ErrNotFound
+	}
+	return &tables[0], nil
+}
+
+// delete removes the domain unless an address still uses it.
+func (r domainRepository) delete(domain string) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var n int
+	if err := tx.Get(&n, `SELECT count(*) FROM addresses WHERE address LIKE '%@' || $1`, domain); err != nil {
+		return err
+	}
+	if n > 0 {
+		return database.ErrInUse
+	}
+	res, err := tx.Exec(`DELETE FROM domains WHERE domain = $1`, domain)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return database.ErrNotFound
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,5 @@
+package domain
+
+type domainTable struct {
+	Domain string `db:"domain"`
+}
@@ -5,4 +5,5 @@ import "fmt"
 var (
 	ErrNotFound error = fmt.Errorf("record not found")
 	ErrSql      error = fmt.Errorf("sql error")
+	ErrInUse    error = fmt.Errorf("record in use")
 )
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/zen-en-tonal/mtw/migrations"
+)
+
+var ErrSchemaBehind = errors.New("schema is behind")
+
+// Version is the state of the schema of a database.
+type Version struct {
+	Current uint // 0 if no migration is applied.
+	Latest  uint // the last migration embedded in the binary.
+	Dirty   bool // a migration failed halfway and has to be fixed by hand.
+}
+
+// Behind reports whether the schema needs migrations or fixing.
+func (v Version) Behind() bool {
+	return v.Dirty || v.Current < v.Latest
+}
+
+// migrations returns the directory of the migrations of the Dialect in migrations.FS.
+func (d Dialect) migrations() string {
+	if d == Postgres {
+		return "postgres"
+	}
+	return "."
+}
+
+func newMigrate(db *sql.DB) (*migrate.Migrate, source.Driver, error) {
+	dialect := DialectOfDB(db)
+	src, err := iofs.New(migrations.FS, dialect.migrations())
+	if err != nil {
+		return nil, nil, err
+	}
+	var driver database.Driver
+	if dialect == Postgres {
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	} else {
+		driver, err = sqlite.WithInstance(db, &sqlite.Config{})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, string(dialect), driver)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, src, nil
+}
+
+// Migrate applies the migrations of the Dialect of the db not applied yet.
+func Migrate(db *sql.DB) error {
+	m, _, err := newMigrate(db)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Rollback reverts the last `steps` migrations.
+func Rollback(db *sql.DB, steps int) error {
+	m, _, err := newMigrate(db)
+	if err != nil {
+		return err
+	}
+	if err := m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// SchemaVersion returns the Version of the schema of the db.
+func SchemaVersion(db *sql.DB) (*Version, error) {
+	m, src, err := newMigrate(db)
+	if err != nil {
+		return nil, err
+	}
+	var v Version
+	v.Current, v.Dirty, err = m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, err
+	}
+	if v.Latest, err = latest(src); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// CheckSchema refuses the schema of the db unless it is up to date.
+//
+// # Errors
+//   - ErrSchemaBehind if a migration is not applied yet or the schema is dirty.
+func CheckSchema(db *sql.DB) error {
+	v, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if v.Dirty {
+		return fmt.Errorf("%w: migration %d is dirty", ErrSchemaBehind, v.Current)
+	}
+	if v.Behind() {
+		return fmt.Errorf("%w: version %d, latest %d", ErrSchemaBehind, v.Current, v.Latest)
+	}
+	return nil
+}
+
+// latest returns the version of the last migration of the source.
+func latest(src source.Driver) (uint, error) {
+	v, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := src.Next(v)
+		if errors.Is(err, fs.ErrNotExist) {
+			return v, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		v = next
+	}
+}
+
+// Drop deletes everything in the db.
+func Drop(db *sql.DB) error {
+	m, _, err := newMigrate(db)
+	if err != nil {
+		return err
+	}
+	return m.Drop()
+}
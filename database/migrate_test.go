@@ -0,0 +1,40 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/migrations"
+)
+
+func TestMigrate(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "mtw.db"))
+	assert.Nil(t, err)
+	defer db.Close()
+
+	v, err := SchemaVersion(db)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), v.Current)
+	assert.True(t, v.Behind())
+	assert.ErrorIs(t, CheckSchema(db), ErrSchemaBehind)
+
+	assert.Nil(t, Migrate(db))
+	assert.Nil(t, Migrate(db))
+	assert.Nil(t, CheckSchema(db))
+
+	assert.Nil(t, Rollback(db, 1))
+	v, err = SchemaVersion(db)
+	assert.Nil(t, err)
+	assert.Equal(t, v.Latest-1, v.Current)
+	assert.ErrorIs(t, CheckSchema(db), ErrSchemaBehind)
+}
+
+func TestMigrations_Postgres(t *testing.T) {
+	src, err := iofs.New(migrations.FS, Postgres.migrations())
+	assert.Nil(t, err)
+	v, err := latest(src)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), v)
+}
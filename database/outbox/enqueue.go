@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database/webhook"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+type Enqueue struct {
+	outboxRepository
+	find webhook.Find
+}
+
+// NewEnqueue returns a handle to persist Transactions for later delivery.
+func NewEnqueue(db *sql.DB) Enqueue {
+	return Enqueue{newRepository(db), webhook.NewFind(db)}
+}
+
+// Send persists the Transaction and queues it for every Webhook
+// registered to each recipient whose Condition the Transaction satisfies.
+func (e Enqueue) Send(t session.Transaction) error {
+	now := time.Now().UTC()
+	var entries []outboxTable
+	for _, rcpt := range t.Rcpts() {
+		hooks, err := e.find.ByTransaction(t.ForRcpt(rcpt))
+		if err != nil {
+			return err
+		}
+		for _, hook := range *hooks {
+			entries = append(entries, newOutboxTable(t.ID, uuid.UUID(hook.ID()), rcpt, now))
+		}
+	}
+	table, err := fromTransaction(t, now)
+	if err != nil {
+		return err
+	}
+	return e.insert(table, entries)
+}
@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database/webhook"
+	"github.com/zen-en-tonal/mtw/queue"
+	"github.com/zen-en-tonal/mtw/session"
+	w "github.com/zen-en-tonal/mtw/webhook"
+)
+
+type Queue struct {
+	outboxRepository
+	find webhook.Find
+}
+
+// NewQueue returns a handle to deliver queued Transactions.
+// `defaults` are applied to every Webhook loaded from the DB.
+func NewQueue(db *sql.DB, defaults ...w.Option) Queue {
+	return Queue{newRepository(db), webhook.NewFind(db, defaults...)}
+}
+
+// Claim returns Jobs that are due at `now` and holds them until `until`.
+// Entries whose Transaction or Webhook cannot be loaded are given up.
+func (q Queue) Claim(now time.Time, until time.Time, limit int) ([]queue.Job, error) {
+	tables, err := q.claim(now, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make(map[uuid.UUID]*session.Transaction)
+	jobs := make([]queue.Job, 0, len(*tables))
+	for _, table := range *tables {
+		trans, ok := transactions[table.TransactionID]
+		if !ok {
+			trans, err = q.transaction(table.TransactionID)
+			if err != nil {
+				if err := q.finish(table, StatusDead, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			transactions[table.TransactionID] = trans
+		}
+		hook, err := q.find.ByID(w.WebhookID(table.WebhookID))
+		if err != nil {
+			if err := q.finish(table, StatusDead, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		t := *trans
+		if table.Rcpt != "" {
+			rcpt, err := session.ParseAddr(table.Rcpt)
+			if err != nil {
+				if err := q.finish(table, StatusDead, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			t = trans.ForRcpt(*rcpt)
+		}
+		jobs = append(jobs, queue.Job{
+			ID:          table.ID,
+			Attempts:    table.Attempts,
+			Transaction: t,
+			Hook:        *hook,
+		})
+	}
+	return jobs, nil
+}
+
+// Done marks the Job as delivered.
+func (q Queue) Done(job queue.Job) error {
+	return q.update(outboxTable{
+		ID:            job.ID,
+		Status:        StatusDelivered,
+		Attempts:      job.Attempts,
+		NextAttemptAt: time.Now().UTC(),
+		UpdatedAt:     time.Now().UTC(),
+	})
+}
+
+// Retry schedules the Job to be attempted again at `next`.
+func (q Queue) Retry(job queue.Job, next time.Time, reason error) error {
+	return q.update(outboxTable{
+		ID:            job.ID,
+		Status:        StatusPending,
+		Attempts:      job.Attempts,
+		NextAttemptAt: next,
+		LastError:     reason.Error(),
+		UpdatedAt:     time.Now().UTC(),
+	})
+}
+
+// Dead moves the Job into the dead-letter state.
+func (q Queue) Dead(job queue.Job, reason error) error {
+	return q.update(outboxTable{
+		ID:            job.ID,
+		Status:        StatusDead,
+		Attempts:      job.Attempts,
+		NextAttemptAt: time.Now().UTC(),
+		LastError:     reason.Error(),
+		UpdatedAt:     time.Now().UTC(),
+	})
+}
+
+func (q Queue) finish(table outboxTable, status string, reason error) error {
+	table.Status = status
+	table.LastError = reason.Error()
+	table.UpdatedAt = time.Now().UTC()
+	return q.update(table)
+}
+
+func (q Queue) transaction(id uuid.UUID) (*session.Transaction, error) {
+	table, err := q.findTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+	return table.into()
+}
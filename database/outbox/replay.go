@@ -0,0 +1,109 @@
+package outbox
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database/webhook"
+	"github.com/zen-en-tonal/mtw/session"
+	w "github.com/zen-en-tonal/mtw/webhook"
+)
+
+type Replay struct {
+	outboxRepository
+	find webhook.Find
+}
+
+// NewReplay returns a handle to queue stored Transactions again.
+func NewReplay(db *sql.DB) Replay {
+	return Replay{newRepository(db), webhook.NewFind(db)}
+}
+
+// All queues the Transaction for every Webhook currently registered to its recipients
+// whose Condition the Transaction satisfies.
+// Returns the IDs of the queued entries.
+//
+// # Errors
+//   - If no Transaction found.
+func (r Replay) All(id uuid.UUID) (*[]uuid.UUID, error) {
+	table, err := r.findTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+	rcpts, err := table.rcpts()
+	if err != nil {
+		return nil, err
+	}
+	trans, err := table.into()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	var entries []outboxTable
+	for _, rcpt := range rcpts {
+		hooks, err := r.find.ByTransaction(trans.ForRcpt(rcpt))
+		if err != nil {
+			return nil, err
+		}
+		for _, hook := range *hooks {
+			entries = append(entries, newOutboxTable(table.ID, uuid.UUID(hook.ID()), rcpt, now))
+		}
+	}
+	return r.replay(entries)
+}
+
+// To queues the Transaction for the Webhook.
+// The Webhook receives it as the first recipient it is registered to,
+// or as the first recipient if it is registered to none of them.
+// Returns the IDs of the queued entries.
+//
+// # Errors
+//   - If no Transaction or Webhook found.
+func (r Replay) To(id uuid.UUID, webhookID w.WebhookID) (*[]uuid.UUID, error) {
+	table, err := r.findTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+	hook, err := r.find.ByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	rcpts, err := table.rcpts()
+	if err != nil {
+		return nil, err
+	}
+	rcpt, err := r.rcptOf(rcpts, hook.ID())
+	if err != nil {
+		return nil, err
+	}
+	entry := newOutboxTable(table.ID, uuid.UUID(hook.ID()), rcpt, time.Now().UTC())
+	return r.replay([]outboxTable{entry})
+}
+
+// rcptOf returns the first recipient the Webhook is registered to.
+func (r Replay) rcptOf(rcpts []session.Address, webhookID w.WebhookID) (session.Address, error) {
+	for _, rcpt := range rcpts {
+		hooks, err := r.find.ByAddr(rcpt)
+		if err != nil {
+			return session.Address{}, err
+		}
+		for _, hook := range *hooks {
+			if hook.ID() == webhookID {
+				return rcpt, nil
+			}
+		}
+	}
+	return rcpts[0], nil
+}
+
+func (r Replay) replay(entries []outboxTable) (*[]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	if err := r.enqueue(entries); err != nil {
+		return nil, err
+	}
+	return &ids, nil
+}
@@ -0,0 +1,196 @@
+package outbox
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+type outboxRepository struct {
+	conn *sqlx.DB
+}
+
+func newRepository(db *sql.DB) outboxRepository {
+	return outboxRepository{database.Conn(db)}
+}
+
+// insert persists a transaction and its outbox entries at once.
+func (r outboxRepository) insert(trans transactionTable, entries []outboxTable) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO transactions (
+			id
+		,	sender
+		,	rcpt
+		,	raw
+		,	auth_user
+		,	auth_results
+		,	remote_addr
+		,	helo
+		,	tls
+		,	created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`,
+		trans.ID,
+		trans.Sender,
+		trans.Rcpt,
+		trans.Raw,
+		trans.AuthUser,
+		trans.AuthResults,
+		trans.RemoteAddr,
+		trans.Helo,
+		trans.TLS,
+		trans.CreatedAt,
+	); err != nil {
+		return err
+	}
+	if err := insertEntries(tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// enqueue persists outbox entries of a stored transaction.
+func (r outboxRepository) enqueue(entries []outboxTable) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertEntries(tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertEntries(tx *sqlx.Tx, entries []outboxTable) error {
+	for _, entry := range entries {
+		if _, err := tx.Exec(`
+			INSERT INTO outbox (
+				id
+			,	transaction_id
+			,	webhook_id
+			,	rcpt
+			,	status
+			,	attempts
+			,	next_attempt_at
+			,	last_error
+			,	created_at
+			,	updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`,
+			entry.ID,
+			entry.TransactionID,
+			entry.WebhookID,
+			entry.Rcpt,
+			entry.Status,
+			entry.Attempts,
+			entry.NextAttemptAt,
+			entry.LastError,
+			entry.CreatedAt,
+			entry.UpdatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claim returns pending entries that are due at `now`
+// and postpones them to `until`.
+// On Postgres, entries claimed by another replica are skipped.
+func (r outboxRepository) claim(now time.Time, until time.Time, limit int) (*[]outboxTable, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	lock := ""
+	if r.conn.DriverName() == string(database.Postgres) {
+		lock = "FOR UPDATE SKIP LOCKED"
+	}
+	var tables []outboxTable
+	if err := tx.Select(&tables, `
+		SELECT
+			outbox.*
+		FROM
+			outbox
+		WHERE
+			status = $1
+		AND next_attempt_at <= $2
+		ORDER BY
+			next_attempt_at
+		LIMIT $3
+		`+lock,
+		StatusPending,
+		now,
+		limit); err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		if _, err := tx.Exec(`
+			UPDATE outbox SET next_attempt_at = $1 WHERE id = $2
+			`,
+			until,
+			table.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r outboxRepository) update(table outboxTable) error {
+	_, err := r.conn.Exec(`
+		UPDATE outbox SET
+			status = $1
+		,	attempts = $2
+		,	next_attempt_at = $3
+		,	last_error = $4
+		,	updated_at = $5
+		WHERE
+			id = $6
+		`,
+		table.Status,
+		table.Attempts,
+		table.NextAttemptAt,
+		table.LastError,
+		table.UpdatedAt,
+		table.ID,
+	)
+	return err
+}
+
+func (r outboxRepository) findTransaction(id uuid.UUID) (*transactionTable, error) {
+	var tables []transactionTable
+	if err := r.conn.Select(&tables, `
+		SELECT
+			transactions.*
+		FROM
+			transactions
+		WHERE
+			id = $1
+		`,
+		id); err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, database.ErrNotFound
+	}
+	table := tables[0]
+	return &table, nil
+}
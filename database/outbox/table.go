@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+const (
+	StatusPending   string = "pending"
+	StatusDelivered string = "delivered"
+	StatusDead      string = "dead"
+)
+
+type transactionTable struct {
+	ID          uuid.UUID `db:"id"`
+	Sender      string    `db:"sender"`
+	Rcpt        string    `db:"rcpt"` // comma separated list of the recipients.
+	Raw         []byte    `db:"raw"`
+	AuthUser    string    `db:"auth_user"`
+	AuthResults string    `db:"auth_results"` // json encoded session.AuthResults.
+	RemoteAddr  string    `db:"remote_addr"`
+	Helo        string    `db:"helo"`
+	TLS         bool      `db:"tls"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func fromTransaction(t session.Transaction, now time.Time) (transactionTable, error) {
+	rcpts := make([]string, len(t.Rcpts()))
+	for i, rcpt := range t.Rcpts() {
+		rcpts[i] = rcpt.String()
+	}
+	var results []byte
+	if t.AuthResults().Verified() {
+		var err error
+		if results, err = json.Marshal(t.AuthResults()); err != nil {
+			return transactionTable{}, err
+		}
+	}
+	return transactionTable{
+		ID:          t.ID,
+		Sender:      t.SenderAddress(),
+		Rcpt:        strings.Join(rcpts, ", "),
+		Raw:         t.Raw(),
+		AuthUser:    t.AuthUser(),
+		AuthResults: string(results),
+		RemoteAddr:  t.RemoteAddr(),
+		Helo:        t.Helo(),
+		TLS:         t.TLS(),
+		CreatedAt:   now,
+	}, nil
+}
+
+// into converts a transactionTable into a Transaction.
+func (t transactionTable) into() (*session.Transaction, error) {
+	sender, err := session.ParseAddr(t.Sender)
+	if err != nil {
+		return nil, err
+	}
+	rcpts, err := t.rcpts()
+	if err != nil {
+		return nil, err
+	}
+	trans, err := session.NewTransactionWithRcpts(t.ID, *sender, rcpts, bytes.NewReader(t.Raw))
+	if err != nil {
+		return nil, err
+	}
+	*trans = trans.FromClient(session.Client{
+		IP:   net.ParseIP(t.RemoteAddr),
+		Helo: t.Helo,
+		TLS:  t.TLS,
+	})
+	if t.AuthUser != "" {
+		*trans = trans.AuthenticatedAs(t.AuthUser)
+	}
+	if t.AuthResults != "" {
+		var results session.AuthResults
+		if err := json.Unmarshal([]byte(t.AuthResults), &results); err != nil {
+			return nil, err
+		}
+		*trans = trans.WithAuthResults(results)
+	}
+	return trans, nil
+}
+
+func (t transactionTable) rcpts() ([]session.Address, error) {
+	list, err := mail.ParseAddressList(t.Rcpt)
+	if err != nil {
+		return nil, err
+	}
+	rcpts := make([]session.Address, len(list))
+	for i, addr := range list {
+		rcpt, err := session.ParseAddr(addr.Address)
+		if err != nil {
+			return nil, err
+		}
+		rcpts[i] = *rcpt
+	}
+	return rcpts, nil
+}
+
+type outboxTable struct {
+	ID            uuid.UUID `db:"id"`
+	TransactionID uuid.UUID `db:"transaction_id"`
+	WebhookID     uuid.UUID `db:"webhook_id"`
+	Rcpt          string    `db:"rcpt"` // the recipient the webhook is registered to.
+	Status        string    `db:"status"`
+	Attempts      int       `db:"attempts"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	LastError     string    `db:"last_error"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+// newOutboxTable returns a pending entry to deliver the transaction to the webhook
+// registered to the recipient.
+func newOutboxTable(transactionID uuid.UUID, webhookID uuid.UUID, rcpt session.Address, now time.Time) outboxTable {
+	return outboxTable{
+		ID:            uuid.New(),
+		TransactionID: transactionID,
+		WebhookID:     webhookID,
+		Rcpt:          rcpt.String(),
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
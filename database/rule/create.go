@@ -0,0 +1,44 @@
+package rule
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/spam"
+)
+
+type CreateHandle struct {
+	ruleRepository
+	session.Address
+}
+
+// Create returns a handle to add sender rules to the Address.
+func Create(db *sql.DB, addr session.Address) CreateHandle {
+	return CreateHandle{newRepository(db), addr}
+}
+
+// One adds a rule to the Address in the context.
+//
+// # Errors
+//   - spam.ErrInvalidRule if the action or the pattern is invalid.
+//   - If the Address does not exist.
+func (c CreateHandle) One(action string, pattern string) (*Rule, error) {
+	r, err := spam.NewRule(action, pattern)
+	if err != nil {
+		return nil, err
+	}
+	table := ruleTable{
+		ID:        uuid.New(),
+		Address:   c.Address.String(),
+		Action:    string(r.Action),
+		Pattern:   r.Pattern,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := c.insert(table); err != nil {
+		return nil, err
+	}
+	rule := table.into()
+	return &rule, nil
+}
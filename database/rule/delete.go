@@ -0,0 +1,26 @@
+package rule
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+type DeleteHandle struct {
+	ruleRepository
+	session.Address
+}
+
+// Delete returns a handle to delete sender rules of the Address.
+func Delete(db *sql.DB, addr session.Address) DeleteHandle {
+	return DeleteHandle{newRepository(db), addr}
+}
+
+// One deletes the rule of the Address in the context.
+//
+// # Errors
+//   - If no rule found.
+func (d DeleteHandle) One(id uuid.UUID) error {
+	return d.delete(d.Address.String(), id.String())
+}
@@ -0,0 +1,47 @@
+package rule
+
+import (
+	"database/sql"
+
+	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/spam"
+)
+
+type FindHandle struct {
+	ruleRepository
+}
+
+// Find returns a handle to get sender rules.
+func Find(db *sql.DB) FindHandle {
+	return FindHandle{newRepository(db)}
+}
+
+// ByAddr returns the rules of the Address in the order they were added.
+func (f FindHandle) ByAddr(addr session.Address) (*[]Rule, error) {
+	tables, err := f.findByAddr(addr.String())
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, len(*tables))
+	for i, table := range *tables {
+		rules[i] = table.into()
+	}
+	return &rules, nil
+}
+
+// AcceptSender checks the sender by the rules of the recipient.
+// A recipient without rules accepts any sender.
+//
+// # Errors
+//   - session.ErrSenderDenied if the rules refuse the sender.
+func (f FindHandle) AcceptSender(sender session.Address, rcpt session.Address) error {
+	rules, err := f.ByAddr(rcpt)
+	if err != nil {
+		return err
+	}
+	xs := make([]spam.Rule, len(*rules))
+	for i, rule := range *rules {
+		xs[i] = rule.Rule
+	}
+	return spam.SenderRulesFilter(xs...).Accept(sender.String())
+}
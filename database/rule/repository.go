@@ -0,0 +1,64 @@
+package rule
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+type ruleRepository struct {
+	conn *sqlx.DB
+}
+
+func newRepository(db *sql.DB) ruleRepository {
+	return ruleRepository{database.Conn(db)}
+}
+
+func (r ruleRepository) insert(table ruleTable) error {
+	_, err := r.conn.Exec(`
+		INSERT INTO sender_rules (
+			id
+		,	address
+		,	action
+		,	pattern
+		,	created_at
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		`,
+		table.ID,
+		table.Address,
+		table.Action,
+		table.Pattern,
+		table.CreatedAt,
+	)
+	return err
+}
+
+func (r ruleRepository) findByAddr(addr string) (*[]ruleTable, error) {
+	var tables []ruleTable
+	if err := r.conn.Select(
+		&tables,
+		`SELECT * FROM sender_rules WHERE address = $1 ORDER BY created_at`,
+		addr); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r ruleRepository) delete(addr string, id string) error {
+	res, err := r.conn.Exec(
+		`DELETE FROM sender_rules WHERE address = $1 AND id = $2`,
+		addr,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package rule
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/spam"
+)
+
+// Rule is a sender rule of an Address.
+type Rule struct {
+	ID uuid.UUID
+	spam.Rule
+	CreatedAt time.Time
+}
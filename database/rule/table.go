@@ -0,0 +1,28 @@
+package rule
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/spam"
+)
+
+type ruleTable struct {
+	ID        uuid.UUID `db:"id"`
+	Address   string    `db:"address"`
+	Action    string    `db:"action"`
+	Pattern   string    `db:"pattern"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// into converts a ruleTable into a Rule.
+func (t ruleTable) into() Rule {
+	return Rule{
+		ID: t.ID,
+		Rule: spam.Rule{
+			Action:  spam.Action(t.Action),
+			Pattern: t.Pattern,
+		},
+		CreatedAt: t.CreatedAt,
+	}
+}
@@ -0,0 +1,68 @@
+package token
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/token"
+)
+
+var ErrEmptyName = errors.New("name must not be empty")
+
+type CreateHandle struct {
+	tokenRepository
+}
+
+// Create returns a handle to issue API tokens.
+func Create(db *sql.DB) CreateHandle {
+	return CreateHandle{newRepository(db)}
+}
+
+// One issues a Token of the owner with the scopes, which expires at `expiresAt` unless nil.
+// Returns the Token and its secret, which is never stored in plain.
+//
+// # Errors
+//   - If the name is empty.
+//   - token.ErrInvalidScope if a scope is unknown or none is given.
+//   - token.ErrEmptyOwner if the owner is empty and the scopes are not admin.
+func (c CreateHandle) One(name string, owner string, scopes []string, expiresAt *time.Time) (*token.Token, string, error) {
+	if name == "" {
+		return nil, "", ErrEmptyName
+	}
+	parsed, err := token.ParseScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := (token.Token{Owner: owner, Scopes: parsed}).Validate(); err != nil {
+		return nil, "", err
+	}
+	secret, err := token.NewSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	names := make([]string, len(parsed))
+	for i, s := range parsed {
+		names[i] = string(s)
+	}
+	if expiresAt != nil {
+		utc := expiresAt.UTC()
+		expiresAt = &utc
+	}
+	table := tokenTable{
+		ID:        uuid.New(),
+		Name:      name,
+		Owner:     owner,
+		TokenHash: token.Hash(secret),
+		Scopes:    strings.Join(names, ","),
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := c.insert(table); err != nil {
+		return nil, "", err
+	}
+	t := table.into()
+	return &t, secret, nil
+}
@@ -0,0 +1,24 @@
+package token
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type DeleteHandle struct {
+	tokenRepository
+}
+
+// Delete returns a handle to revoke API tokens.
+func Delete(db *sql.DB) DeleteHandle {
+	return DeleteHandle{newRepository(db)}
+}
+
+// One revokes the Token.
+//
+// # Errors
+//   - If no Token found.
+func (d DeleteHandle) One(id uuid.UUID) error {
+	return d.delete(id)
+}
@@ -0,0 +1,63 @@
+package token
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/token"
+)
+
+type FindHandle struct {
+	tokenRepository
+}
+
+// Find returns a handle to get API tokens.
+func Find(db *sql.DB) FindHandle {
+	return FindHandle{newRepository(db)}
+}
+
+// All returns an array of Token.
+func (f FindHandle) All() (*[]token.Token, error) {
+	tables, err := f.all()
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]token.Token, len(*tables))
+	for i, table := range *tables {
+		tokens[i] = table.into()
+	}
+	return &tokens, nil
+}
+
+// Exists reports whether any Token has been issued.
+func (f FindHandle) Exists() (bool, error) {
+	n, err := f.count()
+	return n > 0, err
+}
+
+// Authenticate returns the Token of the secret.
+//
+// # Errors
+//   - token.ErrInvalidToken if no Token has the secret or it is expired.
+func (f FindHandle) Authenticate(secret string) (*token.Token, error) {
+	hash := token.Hash(secret)
+	table, err := f.findByHash(hash)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("unknown token: %w", token.ErrInvalidToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(table.TokenHash), []byte(hash)) != 1 {
+		return nil, fmt.Errorf("unknown token: %w", token.ErrInvalidToken)
+	}
+	t := table.into()
+	if t.Expired(time.Now()) {
+		return nil, fmt.Errorf("token %s is expired: %w", t.ID, token.ErrInvalidToken)
+	}
+	return &t, nil
+}
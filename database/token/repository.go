@@ -0,0 +1,82 @@
+package token
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+type tokenRepository struct {
+	conn *sqlx.DB
+}
+
+func newRepository(db *sql.DB) tokenRepository {
+	return tokenRepository{database.Conn(db)}
+}
+
+func (r tokenRepository) insert(table tokenTable) error {
+	_, err := r.conn.Exec(`
+		INSERT INTO api_tokens (
+			id
+		,	name
+		,	owner
+		,	token_hash
+		,	scopes
+		,	expires_at
+		,	created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`,
+		table.ID,
+		table.Name,
+		table.Owner,
+		table.TokenHash,
+		table.Scopes,
+		table.ExpiresAt,
+		table.CreatedAt,
+	)
+	return err
+}
+
+func (r tokenRepository) all() (*[]tokenTable, error) {
+	var tables []tokenTable
+	if err := r.conn.Select(&tables, `SELECT * FROM api_tokens ORDER BY created_at`); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r tokenRepository) findByHash(hash string) (*tokenTable, error) {
+	var tables []tokenTable
+	if err := r.conn.Select(
+		&tables,
+		`SELECT * FROM api_tokens WHERE token_hash = $1`,
+		hash); err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, database.ErrNotFound
+	}
+	return &tables[0], nil
+}
+
+func (r tokenRepository) count() (int, error) {
+	var n int
+	err := r.conn.Get(&n, `SELECT count(*) FROM api_tokens`)
+	return n, err
+}
+
+func (r tokenRepository) delete(id uuid.UUID) error {
+	res, err := r.conn.Exec(`DELETE FROM api_tokens WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package token
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/token"
+)
+
+type tokenTable struct {
+	ID        uuid.UUID  `db:"id"`
+	Name      string     `db:"name"`
+	Owner     string     `db:"owner"`
+	TokenHash string     `db:"token_hash"`
+	Scopes    string     `db:"scopes"` // comma separated.
+	ExpiresAt *time.Time `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// into converts a tokenTable into a Token.
+func (t tokenTable) into() token.Token {
+	var scopes []token.Scope
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if s != "" {
+			scopes = append(scopes, token.Scope(s))
+		}
+	}
+	return token.Token{
+		ID:        t.ID,
+		Name:      t.Name,
+		Owner:     t.Owner,
+		Scopes:    scopes,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
@@ -8,39 +8,98 @@ import (
 	"github.com/zen-en-tonal/mtw/webhook"
 )
 
-type Create struct{ webhookRepository }
+type Create struct {
+	webhookRepository
+	owner string
+}
 
 // NewCreate returns a handle to create and persist a Webhook.
 func NewCreate(db *sql.DB) Create {
-	return Create{newRepository(db)}
+	return Create{newRepository(db), ""}
+}
+
+// OwnedBy returns a handle to create Webhooks owned by the owner.
+// Updating a Webhook keeps its owner.
+func (c Create) OwnedBy(owner string) Create {
+	c.owner = owner
+	return c
 }
 
+// persist inserts a new Webhook.
 func (c Create) persist(table webhookTable) (*webhook.Webhook, error) {
 	hook, err := table.into()
 	if err != nil {
 		return nil, err
 	}
-	if err := c.upsert(table); err != nil {
+	if err := c.insert(table); err != nil {
 		return nil, err
 	}
 	return hook, nil
 }
 
 // FromBlueprint creates and persist a Webhook from the Blueprint.
+// The Webhook always gets a new ID, and the ID of the Blueprint is ignored,
+// so that it never overwrites another Webhook.
+//
+// # Errors
+//   - webhook.ValidationError if the Blueprint is invalid.
 func (c Create) FromBlueprint(bp webhook.Blueprint) (*webhook.Webhook, error) {
+	bp.ID = ""
+	table, err := c.table(bp)
+	if err != nil {
+		return nil, err
+	}
+	return c.persist(*table)
+}
+
+// table returns the row of the Blueprint, with a new ID if the Blueprint has none.
+func (c Create) table(bp webhook.Blueprint) (*webhookTable, error) {
+	if err := bp.Validate(); err != nil {
+		return nil, err
+	}
 	wh, err := webhook.FromBlueprint(bp)
 	if err != nil {
 		return nil, err
 	}
-	table := webhookTable{
+	return &webhookTable{
 		ID:          uuid.UUID(wh.ID()),
 		Endpoint:    bp.Endpoint,
 		Auth:        bp.Auth,
 		Schema:      bp.Schema,
+		SchemaMode:  bp.SchemaMode,
 		Method:      bp.Method,
 		ContentType: bp.ContentType,
+		Secret:      bp.Secret,
+		Owner:       c.owner,
+	}, nil
+}
+
+// Update replaces the Webhook that has the same ID as the Blueprint.
+// An empty secret keeps the current one.
+//
+// # Errors
+//   - If no Webhook found.
+//   - webhook.ValidationError if the Blueprint is invalid.
+func (c Create) Update(bp webhook.Blueprint) (*webhook.Webhook, error) {
+	id, err := uuid.Parse(bp.ID)
+	if err != nil {
+		return nil, err
 	}
-	return c.persist(table)
+	table, err := c.findOne(webhook.WebhookID(id))
+	if err != nil {
+		return nil, err
+	}
+	if bp.Secret == "" {
+		bp.Secret = table.Secret
+	}
+	updated, err := c.table(bp)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.upsert(*updated); err != nil {
+		return nil, err
+	}
+	return updated.into()
 }
 
 // ForGet creates and persist a Webhook to send a GET request.
@@ -66,3 +125,24 @@ func (c Create) ForPost(endpoint string, schema string, contentType string, auth
 	}
 	return c.persist(table)
 }
+
+// RotateSecret replaces the secret of the Webhook with a random one.
+// Returns the new secret.
+//
+// # Errors
+//   - If no Webhook found.
+func (c Create) RotateSecret(id webhook.WebhookID) (string, error) {
+	table, err := c.findOne(id)
+	if err != nil {
+		return "", err
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return "", err
+	}
+	table.Secret = secret
+	if err := c.upsert(*table); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
@@ -0,0 +1,23 @@
+package webhook
+
+import (
+	"database/sql"
+
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+type Delete struct{ webhookRepository }
+
+// NewDelete returns a handle to delete Webhooks.
+func NewDelete(db *sql.DB) Delete {
+	return Delete{newRepository(db)}
+}
+
+// ByID deletes the Webhook and unregisters it from every Address.
+// Deliveries still queued for it are dropped.
+//
+// # Errors
+//   - If no Webhook found.
+func (d Delete) ByID(id webhook.WebhookID) error {
+	return d.delete(id)
+}
@@ -3,6 +3,7 @@ package webhook
 import (
 	"database/sql"
 
+	"github.com/zen-en-tonal/mtw/database"
 	"github.com/zen-en-tonal/mtw/session"
 	"github.com/zen-en-tonal/mtw/webhook"
 )
@@ -34,6 +35,66 @@ func (f Find) ByAddr(addr session.Address) (*[]webhook.Webhook, error) {
 	return &hooks, nil
 }
 
+// Link is a Webhook linked to an Address, with the Condition of the link.
+type Link struct {
+	webhook.Webhook
+	Condition webhook.Condition
+}
+
+// Links returns the Webhooks linked to the Address with their Conditions.
+func (f Find) Links(addr session.Address) (*[]Link, error) {
+	tables, err := f.findByAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]Link, len(*tables))
+	for i, table := range *tables {
+		hook, err := table.into(f.options...)
+		if err != nil {
+			return nil, err
+		}
+		links[i] = Link{*hook, table.condition()}
+	}
+	return &links, nil
+}
+
+// Link returns the link between the Address and the Webhook.
+//
+// # Errors
+//   - If the Webhook is not linked to the Address.
+func (f Find) Link(addr session.Address, id webhook.WebhookID) (*Link, error) {
+	links, err := f.Links(addr)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range *links {
+		if link.ID() == id {
+			return &link, nil
+		}
+	}
+	return nil, database.ErrNotFound
+}
+
+// ByTransaction returns the Webhooks linked to the recipient of the Transaction
+// whose Conditions the Transaction satisfies.
+func (f Find) ByTransaction(t session.Transaction) (*[]webhook.Webhook, error) {
+	rcpt, err := session.ParseAddr(t.RcptAddress())
+	if err != nil {
+		return nil, err
+	}
+	links, err := f.Links(*rcpt)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []webhook.Webhook
+	for _, link := range *links {
+		if link.Condition.Match(t) {
+			hooks = append(hooks, link.Webhook)
+		}
+	}
+	return &hooks, nil
+}
+
 // ByID returns a Webhook by WebhookID.
 //
 // # Errors
@@ -50,6 +111,19 @@ func (f Find) ByID(id webhook.WebhookID) (*webhook.Webhook, error) {
 	return hook, nil
 }
 
+// Blueprint returns the Blueprint of a Webhook as stored.
+//
+// # Errors
+//   - If no Webhook found.
+func (f Find) Blueprint(id webhook.WebhookID) (*webhook.Blueprint, error) {
+	table, err := f.findOne(id)
+	if err != nil {
+		return nil, err
+	}
+	bp := table.blueprint()
+	return &bp, nil
+}
+
 // All returns an array of Webhook.
 func (f Find) All() (*[]webhook.Webhook, error) {
 	tables, err := f.findAll()
@@ -67,14 +141,58 @@ func (f Find) All() (*[]webhook.Webhook, error) {
 	return &hooks, nil
 }
 
+// OwnedBy returns an array of Webhook owned by the owner.
+func (f Find) OwnedBy(owner string) (*[]webhook.Webhook, error) {
+	tables, err := f.findOwnedBy(owner)
+	if err != nil {
+		return nil, err
+	}
+	hooks := make([]webhook.Webhook, len(*tables))
+	for i, table := range *tables {
+		hook, err := table.into(f.options...)
+		if err != nil {
+			return nil, err
+		}
+		hooks[i] = *hook
+	}
+	return &hooks, nil
+}
+
+// Owner returns the owner of the Webhook.
+//
+// # Errors
+//   - If no Webhook found.
+func (f Find) Owner(id webhook.WebhookID) (string, error) {
+	table, err := f.findOne(id)
+	if err != nil {
+		return "", err
+	}
+	return table.Owner, nil
+}
+
+// FindHooks returns the Webhooks linked to the Address.
+// Each Webhook sends only Transactions satisfying the Condition of its link.
 func (f Find) FindHooks(addr session.Address) ([]session.Hook, error) {
-	webhooks, err := f.ByAddr(addr)
+	links, err := f.Links(addr)
 	if err != nil {
 		return nil, err
 	}
-	hooks := make([]session.Hook, len(*webhooks))
-	for i, webhook := range *webhooks {
-		hooks[i] = webhook
+	hooks := make([]session.Hook, len(*links))
+	for i, link := range *links {
+		hooks[i] = routedHook{link.Webhook, link.Condition}
 	}
 	return hooks, nil
 }
+
+// routedHook sends Transactions satisfying the Condition and ignores the others.
+type routedHook struct {
+	session.Hook
+	condition webhook.Condition
+}
+
+func (h routedHook) Send(t session.Transaction) error {
+	if !h.condition.Match(t) {
+		return nil
+	}
+	return h.Hook.Send(t)
+}
@@ -0,0 +1,25 @@
+package webhook
+
+import (
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+// postgresRepository stores Webhooks in Postgres,
+// whose schema deletes the links and the queued deliveries of a webhook by cascade.
+type postgresRepository struct {
+	sqliteRepository
+}
+
+func (r postgresRepository) delete(id webhook.WebhookID) error {
+	res, err := r.conn.Exec(`DELETE FROM webhooks WHERE id = $1`, id.String())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
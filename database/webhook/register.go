@@ -18,10 +18,27 @@ func NewRegistry(db *sql.DB, addr session.Address) Registry {
 }
 
 // Create registers the Webhook to the Address in the context.
+//
+// # Errors
+//   - database.ErrNotFound if the Address or the Webhook does not exist.
 func (r Registry) Create(id webhook.WebhookID) error {
 	return r.insertAddressWebhook(r.Address, id)
 }
 
+// Route links the Webhook to the Address in the context with the Condition,
+// or replaces the Condition if already linked.
+// The Webhook receives only Transactions satisfying the Condition.
+//
+// # Errors
+//   - webhook.ErrInvalidCondition if the Condition is malformed.
+//   - database.ErrNotFound if the Address or the Webhook does not exist.
+func (r Registry) Route(id webhook.WebhookID, c webhook.Condition) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return r.upsertAddressWebhook(r.Address, id, c)
+}
+
 // Remove deletes the Webhook on the Address in the context.
 func (r Registry) Remove(id webhook.WebhookID) error {
 	return r.deleteAddressWebhook(r.Address, id)
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+// sqliteRepository stores Webhooks in SQLite.
+// Its queries are portable, and are shared with postgresRepository.
+type sqliteRepository struct {
+	conn *sqlx.DB
+}
+
+func (r sqliteRepository) insert(table webhookTable) error {
+	_, err := r.conn.Exec(`
+		INSERT INTO webhooks (
+			id
+		,	endpoint
+		,	auth
+		,	schema
+		,	method
+		,	content_type
+		,	secret
+		,	schema_mode
+		,	owner
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`,
+		table.ID,
+		table.Endpoint,
+		table.Auth,
+		table.Schema,
+		table.Method,
+		table.ContentType,
+		table.Secret,
+		table.SchemaMode,
+		table.Owner,
+	)
+	return err
+}
+
+func (r sqliteRepository) upsert(table webhookTable) error {
+	_, err := r.conn.Exec(`
+		INSERT INTO webhooks (
+			id
+		,	endpoint
+		,	auth
+		,	schema
+		,	method
+		,	content_type
+		,	secret
+		,	schema_mode
+		,	owner
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id)
+		DO
+		UPDATE SET
+			endpoint = $2
+		,	auth = $3
+		,	schema = $4
+		,	method = $5
+		,	content_type = $6
+		,	secret = $7
+		,	schema_mode = $8
+		`,
+		table.ID,
+		table.Endpoint,
+		table.Auth,
+		table.Schema,
+		table.Method,
+		table.ContentType,
+		table.Secret,
+		table.SchemaMode,
+		table.Owner,
+	)
+	return err
+}
+
+func (r sqliteRepository) delete(id webhook.WebhookID) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM addresses_webhooks WHERE webhook_id = $1`, id.String()); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM outbox WHERE webhook_id = $1`, id.String()); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM webhooks WHERE id = $1`, id.String())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return database.ErrNotFound
+	}
+	return tx.Commit()
+}
+
+func (r sqliteRepository) findOne(id webhook.WebhookID) (*webhookTable, error) {
+	var tables []webhookTable
+	if err := r.conn.Select(&tables, `
+		SELECT
+			webhooks.*
+		FROM
+			webhooks
+		WHERE
+			id = $1
+		`,
+		id.String()); err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, database.ErrNotFound
+	}
+	table := tables[0]
+	return &table, nil
+}
+
+func (r sqliteRepository) findAll() (*[]webhookTable, error) {
+	var tables []webhookTable
+	if err := r.conn.Select(&tables, `SELECT webhooks.* FROM webhooks`); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r sqliteRepository) findOwnedBy(owner string) (*[]webhookTable, error) {
+	var tables []webhookTable
+	if err := r.conn.Select(&tables, `SELECT webhooks.* FROM webhooks WHERE owner = $1`, owner); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+func (r sqliteRepository) findByAddr(addr session.Address) (*[]linkTable, error) {
+	var tables []linkTable
+	if err := r.conn.Select(&tables, `
+		SELECT
+			webhooks.*
+		,	addresses_webhooks.subject_pattern
+		,	addresses_webhooks.sender_pattern
+		,	addresses_webhooks.header_name
+		,	addresses_webhooks.header_value
+		,	addresses_webhooks.body_contains
+		FROM
+			webhooks
+			JOIN
+				addresses_webhooks ON webhooks.id = addresses_webhooks.webhook_id
+		WHERE
+			addresses_webhooks.address = $1
+		`,
+		addr.String()); err != nil {
+		return nil, err
+	}
+	return &tables, nil
+}
+
+// linkable refuses to link an Address or a Webhook that does not exist,
+// rather than leaving it to the foreign keys.
+func linkable(tx *sqlx.Tx, addr session.Address, webhookID webhook.WebhookID) error {
+	var n int
+	if err := tx.Get(&n, `SELECT count(*) FROM addresses WHERE address = $1`, addr.String()); err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: address %s", database.ErrNotFound, addr)
+	}
+	if err := tx.Get(&n, `SELECT count(*) FROM webhooks WHERE id = $1`, webhookID.String()); err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: webhook %s", database.ErrNotFound, webhookID)
+	}
+	return nil
+}
+
+func (r sqliteRepository) insertAddressWebhook(addr session.Address, webhookID webhook.WebhookID) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := linkable(tx, addr, webhookID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO addresses_webhooks (
+			address
+		, 	webhook_id
+		)
+		VALUES (
+			$1
+		, 	$2
+		)`,
+		addr.String(),
+		webhookID.String()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r sqliteRepository) upsertAddressWebhook(addr session.Address, webhookID webhook.WebhookID, c webhook.Condition) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := linkable(tx, addr, webhookID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO addresses_webhooks (
+			address
+		,	webhook_id
+		,	subject_pattern
+		,	sender_pattern
+		,	header_name
+		,	header_value
+		,	body_contains
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (address, webhook_id)
+		DO
+		UPDATE SET
+			subject_pattern = $3
+		,	sender_pattern = $4
+		,	header_name = $5
+		,	header_value = $6
+		,	body_contains = $7
+		`,
+		addr.String(),
+		webhookID.String(),
+		c.Subject,
+		c.Sender,
+		c.HeaderName,
+		c.HeaderValue,
+		c.BodyContains,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r sqliteRepository) deleteAddressWebhook(addr session.Address, webhookID webhook.WebhookID) error {
+	_, err := r.conn.Exec(`
+		DELETE FROM addresses_webhooks
+		WHERE
+			address = $1
+		AND webhook_id = $2
+		`,
+		addr.String(),
+		webhookID.String())
+	return err
+}
@@ -10,19 +10,48 @@ type webhookTable struct {
 	Endpoint    string    `db:"endpoint"`
 	Auth        string    `db:"auth"`
 	Schema      string    `db:"schema"`
+	SchemaMode  string    `db:"schema_mode"`
 	Method      string    `db:"method"`
 	ContentType string    `db:"content_type"`
+	Secret      string    `db:"secret"`
+	Owner       string    `db:"owner"`
 }
 
 // into converts a webhookTable into a Webhook.
 func (w webhookTable) into(defaults ...webhook.Option) (*webhook.Webhook, error) {
-	bp := webhook.Blueprint{
+	return webhook.FromBlueprint(w.blueprint(), defaults...)
+}
+
+// blueprint returns the Blueprint as stored.
+func (w webhookTable) blueprint() webhook.Blueprint {
+	return webhook.Blueprint{
 		ID:          w.ID.String(),
 		Endpoint:    w.Endpoint,
 		Auth:        w.Auth,
 		Schema:      w.Schema,
+		SchemaMode:  w.SchemaMode,
 		Method:      w.Method,
 		ContentType: w.ContentType,
+		Secret:      w.Secret,
+	}
+}
+
+// linkTable is a Webhook linked to an Address with the Condition of the link.
+type linkTable struct {
+	webhookTable
+	SubjectPattern string `db:"subject_pattern"`
+	SenderPattern  string `db:"sender_pattern"`
+	HeaderName     string `db:"header_name"`
+	HeaderValue    string `db:"header_value"`
+	BodyContains   string `db:"body_contains"`
+}
+
+func (l linkTable) condition() webhook.Condition {
+	return webhook.Condition{
+		Subject:      l.SubjectPattern,
+		Sender:       l.SenderPattern,
+		HeaderName:   l.HeaderName,
+		HeaderValue:  l.HeaderValue,
+		BodyContains: l.BodyContains,
 	}
-	return webhook.FromBlueprint(bp, defaults...)
 }
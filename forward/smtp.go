@@ -1,18 +1,27 @@
 package forward
 
 import (
+	"net"
 	"net/smtp"
 
 	"github.com/zen-en-tonal/mtw/session"
 )
 
+// DefaultPort is the port of the SMTP server unless the host has one.
+const DefaultPort = "587"
+
 type Forwarder struct {
 	auth       smtp.Auth
 	recipients []string
 	host       string
 }
 
+// NewSmtp returns a Hook forwarding mails to the recipients through the SMTP server.
+// The host is like `smtp.example.com:587`, and the port defaults to DefaultPort.
 func NewSmtp(host string, auth smtp.Auth, recp ...string) Forwarder {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, DefaultPort)
+	}
 	return Forwarder{
 		auth:       auth,
 		recipients: recp,
@@ -21,5 +30,5 @@ func NewSmtp(host string, auth smtp.Auth, recp ...string) Forwarder {
 }
 
 func (f Forwarder) Send(t session.Transaction) error {
-	return smtp.SendMail(f.host+":587", f.auth, t.From(), f.recipients, t.Raw())
+	return smtp.SendMail(f.host, f.auth, t.From(), f.recipients, t.Raw())
 }
@@ -2,14 +2,18 @@ package http
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/database/address"
 	"github.com/zen-en-tonal/mtw/session"
 	"github.com/zen-en-tonal/mtw/webhook"
 )
@@ -58,7 +62,7 @@ func TestGetAddresses_Error(t *testing.T) {
 func TestNewAddress(t *testing.T) {
 	router := gin.Default()
 	newAddrRoute(addressService{
-		create: func(user string) (*session.Address, error) {
+		create: func(owner string, user string, domain string) (*session.Address, error) {
 			return session.NewAddr(user, "mail.com")
 		},
 	}).register(router)
@@ -75,10 +79,30 @@ func TestNewAddress(t *testing.T) {
 	assert.Equal(t, `{"address":"alice@mail.com"}`, w.Body.String())
 }
 
+func TestNewAddress_Domain(t *testing.T) {
+	router := gin.Default()
+	newAddrRoute(addressService{
+		create: func(owner string, user string, domain string) (*session.Address, error) {
+			return session.NewAddr(user, domain)
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/address/user/alice?domain=example.com",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"address":"alice@example.com"}`, w.Body.String())
+}
+
 func TestNewRandom(t *testing.T) {
 	router := gin.Default()
 	newAddrRoute(addressService{
-		createRandom: func() (*session.Address, error) {
+		createRandom: func(owner string, domain string) (*session.Address, error) {
 			return session.NewAddr("alice", "mail.com")
 		},
 	}).register(router)
@@ -95,6 +119,25 @@ func TestNewRandom(t *testing.T) {
 	assert.Equal(t, `{"address":"alice@mail.com"}`, w.Body.String())
 }
 
+func TestNewRandom_UnknownDomain(t *testing.T) {
+	router := gin.Default()
+	newAddrRoute(addressService{
+		createRandom: func(owner string, domain string) (*session.Address, error) {
+			return nil, fmt.Errorf("%w: %s", address.ErrUnknownDomain, domain)
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/address/user/random?domain=unknown.com",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestHooks(t *testing.T) {
 	router := gin.Default()
 	wh := webhook.New("http://endpoint.com", webhook.WithID(uuid.MustParse(
@@ -246,3 +289,121 @@ func Test_DELETE_Hook_BadHookID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func Test_DELETE_Address(t *testing.T) {
+	router := gin.Default()
+	newAddrRoute(addressService{
+		remove: func(addr session.Address) error {
+			return nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"DELETE",
+		"/address/alice@mail.com",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func Test_DELETE_Address_NotFound(t *testing.T) {
+	router := gin.Default()
+	newAddrRoute(addressService{
+		remove: func(addr session.Address) error {
+			return database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"DELETE",
+		"/address/alice@mail.com",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PUT_Route(t *testing.T) {
+	var cond webhook.Condition
+	router := gin.Default()
+	newAddrRoute(addressService{
+		routeHook: func(addr session.Address, id webhook.WebhookID, c webhook.Condition) error {
+			cond = c
+			return c.Validate()
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"PUT",
+		"/address/alice@mail.com/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		strings.NewReader(`{"subject":"CRITICAL","header_name":"X-Priority","header_value":"1"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, webhook.Condition{Subject: "CRITICAL", HeaderName: "X-Priority", HeaderValue: "1"}, cond)
+}
+
+func Test_PUT_Route_Invalid(t *testing.T) {
+	router := gin.Default()
+	newAddrRoute(addressService{
+		routeHook: func(addr session.Address, id webhook.WebhookID, c webhook.Condition) error {
+			return c.Validate()
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"PUT",
+		"/address/alice@mail.com/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		strings.NewReader(`{"subject":"("}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_PUT_Route_NotFound(t *testing.T) {
+	router := gin.Default()
+	newAddrRoute(addressService{
+		routeHook: func(addr session.Address, id webhook.WebhookID, c webhook.Condition) error {
+			return database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"PUT",
+		"/address/alice@mail.com/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		strings.NewReader(`{"subject":"CRITICAL"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GET_Route(t *testing.T) {
+	router := gin.Default()
+	newAddrRoute(addressService{
+		getRoute: func(addr session.Address, id webhook.WebhookID) (*webhook.Condition, error) {
+			return &webhook.Condition{Sender: "github.com"}, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"GET",
+		"/address/alice@mail.com/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"subject":"","sender":"github.com","header_name":"","header_value":"","body_contains":""}`, w.Body.String())
+}
@@ -1,21 +1,28 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/database/address"
 	"github.com/zen-en-tonal/mtw/session"
 	"github.com/zen-en-tonal/mtw/webhook"
 )
 
 type addressService struct {
-	create       func(user string) (*session.Address, error)
-	createRandom func() (*session.Address, error)
+	create       func(owner string, user string, domain string) (*session.Address, error)
+	createRandom func(owner string, domain string) (*session.Address, error)
 	getAll       func() (*[]session.Address, error)
+	getOwned     func(owner string) (*[]session.Address, error)
 	getHooks     func(addr session.Address) (*[]webhook.Webhook, error)
 	createHook   func(addr session.Address, id webhook.WebhookID) error
 	removeHook   func(addr session.Address, id webhook.WebhookID) error
+	routeHook    func(addr session.Address, id webhook.WebhookID, c webhook.Condition) error
+	getRoute     func(addr session.Address, id webhook.WebhookID) (*webhook.Condition, error)
+	remove       func(addr session.Address) error
 }
 
 type addressRoute struct {
@@ -23,17 +30,52 @@ type addressRoute struct {
 	Logger
 }
 
-func (r addressRoute) register(e *gin.Engine) {
+func (r addressRoute) register(e gin.IRoutes) {
 	e.GET("/addresses", r.all)
 	e.POST("/address/user/random", r.newRandom)
 	e.POST("/address/user/:user", r.new)
+	e.DELETE("/address/:addr", r.delete)
 	e.GET("/address/:addr/webhooks", r.hooks)
 	e.POST("/address/:addr/webhook/:whid", r.newHook)
 	e.DELETE("/address/:addr/webhook/:whid", r.deleteHook)
+	e.GET("/address/:addr/webhook/:whid", r.route)
+	e.PUT("/address/:addr/webhook/:whid", r.setRoute)
 }
 
+// conditionJson is the Condition of a link. Empty fields match any mail.
+type conditionJson struct {
+	Subject      string `json:"subject"`
+	Sender       string `json:"sender"`
+	HeaderName   string `json:"header_name"`
+	HeaderValue  string `json:"header_value"`
+	BodyContains string `json:"body_contains"`
+}
+
+func fromCondition(c webhook.Condition) conditionJson {
+	return conditionJson{
+		Subject:      c.Subject,
+		Sender:       c.Sender,
+		HeaderName:   c.HeaderName,
+		HeaderValue:  c.HeaderValue,
+		BodyContains: c.BodyContains,
+	}
+}
+
+func (c conditionJson) into() webhook.Condition {
+	return webhook.Condition{
+		Subject:      c.Subject,
+		Sender:       c.Sender,
+		HeaderName:   c.HeaderName,
+		HeaderValue:  c.HeaderValue,
+		BodyContains: c.BodyContains,
+	}
+}
+
+// new creates an Address on the domain given by the `domain` query.
+// The default domain is used if it is omitted.
+// The Address belongs to the owner of the token.
 func (a addressRoute) new(c *gin.Context) {
-	addr, err := a.create(c.Param("user"))
+	addr, err := a.create(ownerOf(c), c.Param("user"), c.Query("domain"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -41,8 +83,14 @@ func (a addressRoute) new(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"address": addr.String()})
 }
 
+// newRandom creates an Address with a random user on the domain given by the `domain` query,
+// as `new` does.
 func (a addressRoute) newRandom(c *gin.Context) {
-	addr, err := a.createRandom()
+	addr, err := a.createRandom(ownerOf(c), c.Query("domain"))
+	if errors.Is(err, address.ErrUnknownDomain) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		a.Logger.Error("New", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -51,8 +99,33 @@ func (a addressRoute) newRandom(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"address": addr.String()})
 }
 
+// delete removes the Address, its links to Webhooks and its sender rules.
+func (a addressRoute) delete(c *gin.Context) {
+	addr, err := session.ParseAddr(c.Param("addr"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = a.remove(*addr)
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		a.Logger.Error("delete", "error", err, "addr", addr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// all returns the Addresses of the owner, or every Address for admins.
 func (a addressRoute) all(c *gin.Context) {
-	addrs, err := a.getAll()
+	get := a.getAll
+	if owner, restricted := filterOf(c); restricted {
+		get = func() (*[]session.Address, error) { return a.getOwned(owner) }
+	}
+	addrs, err := get()
 	if err != nil {
 		a.Logger.Error("All", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -99,7 +172,13 @@ func (r addressRoute) newHook(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := r.createHook(*addr, webhook.WebhookID(hookID)); err != nil {
+	err = r.createHook(*addr, webhook.WebhookID(hookID))
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("newHook", "error", err, "addr", addr)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -123,3 +202,63 @@ func (r addressRoute) deleteHook(c *gin.Context) {
 	}
 	c.Status(http.StatusOK)
 }
+
+// route returns the Condition of the link between the Address and the Webhook.
+func (r addressRoute) route(c *gin.Context) {
+	addr, err := session.ParseAddr(c.Param("addr"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hookID, err := uuid.Parse(c.Param("whid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cond, err := r.getRoute(*addr, webhook.WebhookID(hookID))
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("route", "error", err, "addr", addr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, fromCondition(*cond))
+}
+
+// setRoute links the Webhook to the Address with the Condition,
+// or replaces the Condition if already linked.
+func (r addressRoute) setRoute(c *gin.Context) {
+	addr, err := session.ParseAddr(c.Param("addr"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hookID, err := uuid.Parse(c.Param("whid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var form conditionJson
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = r.routeHook(*addr, webhook.WebhookID(hookID), form.into())
+	if errors.Is(err, webhook.ErrInvalidCondition) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("setRoute", "error", err, "addr", addr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/token"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+// principalKey is the key of the Token authenticated for the request.
+const principalKey = "mtw.principal"
+
+type authService struct {
+	verify       func(secret string) (*token.Token, error)
+	addressOwner func(addr session.Address) (string, error)
+	webhookOwner func(id webhook.WebhookID) (string, error)
+}
+
+type authGuard struct {
+	authService
+	Logger
+}
+
+// authenticate requires a bearer Token allowed to read for GET and HEAD,
+// or to write for the other methods.
+func (g authGuard) authenticate(c *gin.Context) {
+	secret, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || secret == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "bearer token is required"})
+		return
+	}
+	t, err := g.verify(secret)
+	if errors.Is(err, token.ErrInvalidToken) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		g.Logger.Error("authenticate", "error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set(principalKey, t)
+
+	scope := token.Write
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		scope = token.Read
+	}
+	if !t.Allows(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "token requires the " + string(scope) + " scope"})
+		return
+	}
+	c.Next()
+}
+
+// require aborts unless the Token has the Scope.
+func require(scope token.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if t := principal(c); t != nil && !t.Allows(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "token requires the " + string(scope) + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// principal returns the Token of the request, or nil if the route is not guarded.
+func principal(c *gin.Context) *token.Token {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	t, _ := v.(*token.Token)
+	return t
+}
+
+// ownerOf returns the owner of the resources created by the request.
+func ownerOf(c *gin.Context) string {
+	if t := principal(c); t != nil {
+		return t.Owner
+	}
+	return ""
+}
+
+// filterOf returns the owner whose resources the request is restricted to.
+// Admins and unguarded routes are not restricted.
+func filterOf(c *gin.Context) (owner string, restricted bool) {
+	t := principal(c)
+	if t == nil || t.IsAdmin() {
+		return "", false
+	}
+	return t.Owner, true
+}
+
+// ownsAddress responds 404 if the Address in the param belongs to another owner,
+// so that tenants cannot tell the resources of others exist.
+func (g authGuard) ownsAddress(param string) gin.HandlerFunc {
+	return g.owns(param, func(v string) (string, error) {
+		addr, err := session.ParseAddr(v)
+		if err != nil {
+			return "", errMalformed
+		}
+		return g.addressOwner(*addr)
+	})
+}
+
+// ownsWebhook responds 404 if the Webhook in the param belongs to another owner.
+func (g authGuard) ownsWebhook(param string) gin.HandlerFunc {
+	return g.owns(param, func(v string) (string, error) {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return "", errMalformed
+		}
+		return g.webhookOwner(webhook.WebhookID(id))
+	})
+}
+
+// errMalformed is returned by the owner lookups when the param cannot be parsed.
+var errMalformed = errors.New("malformed param")
+
+// owns leaves the resources not found and the malformed params to the handlers.
+func (g authGuard) owns(param string, lookup func(v string) (string, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, restricted := filterOf(c)
+		v := c.Param(param)
+		if !restricted || v == "" {
+			c.Next()
+			return
+		}
+		owner, err := lookup(v)
+		if errors.Is(err, errMalformed) || errors.Is(err, database.ErrNotFound) {
+			c.Next()
+			return
+		}
+		if err != nil {
+			g.Logger.Error("owns", "error", err, param, v)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if owner != filter {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": database.ErrNotFound.Error()})
+			return
+		}
+		c.Next()
+	}
+}
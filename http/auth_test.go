@@ -0,0 +1,195 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+	dbtoken "github.com/zen-en-tonal/mtw/database/token"
+	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/token"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+var testTokens = map[string]token.Token{
+	"admin":  {Name: "admin", Scopes: []token.Scope{token.Admin}},
+	"alice":  {Name: "alice", Owner: "alice", Scopes: []token.Scope{token.Write}},
+	"reader": {Name: "reader", Owner: "alice", Scopes: []token.Scope{token.Read}},
+}
+
+func newGuard() authGuard {
+	return authGuard{
+		authService{
+			verify: func(secret string) (*token.Token, error) {
+				t, ok := testTokens[secret]
+				if !ok {
+					return nil, fmt.Errorf("unknown token: %w", token.ErrInvalidToken)
+				}
+				return &t, nil
+			},
+			addressOwner: func(addr session.Address) (string, error) {
+				switch addr.User() {
+				case "alice":
+					return "alice", nil
+				case "bob":
+					return "bob", nil
+				}
+				return "", database.ErrNotFound
+			},
+			webhookOwner: func(id webhook.WebhookID) (string, error) {
+				return "bob", nil
+			},
+		},
+		slog.Default(),
+	}
+}
+
+func newGuardedRouter(addr addressService) *gin.Engine {
+	router := gin.Default()
+	guard := newGuard()
+	api := router.Group("", guard.authenticate)
+	newAddrRoute(addr).register(api.Group("", guard.ownsAddress("addr"), guard.ownsWebhook("whid")))
+	newDomainsRoute(domainService{
+		getAll: func() (*[]string, error) { return &[]string{}, nil },
+	}).register(api.Group("", require(token.Admin)))
+	return router
+}
+
+func serveAs(router *gin.Engine, secret string, method string, url string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, url, nil)
+	if secret != "" {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthenticate(t *testing.T) {
+	router := newGuardedRouter(addressService{
+		getAll: func() (*[]session.Address, error) { return &[]session.Address{}, nil },
+		getOwned: func(owner string) (*[]session.Address, error) {
+			return &[]session.Address{}, nil
+		},
+		remove: func(addr session.Address) error { return nil },
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, serveAs(router, "", "GET", "/addresses").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveAs(router, "unknown", "GET", "/addresses").Code)
+	assert.Equal(t, http.StatusOK, serveAs(router, "reader", "GET", "/addresses").Code)
+	assert.Equal(t, http.StatusForbidden, serveAs(router, "reader", "DELETE", "/address/alice@mail.com").Code)
+	assert.Equal(t, http.StatusOK, serveAs(router, "alice", "DELETE", "/address/alice@mail.com").Code)
+}
+
+func TestRequireAdmin(t *testing.T) {
+	router := newGuardedRouter(addressService{})
+
+	assert.Equal(t, http.StatusForbidden, serveAs(router, "alice", "GET", "/domains").Code)
+	assert.Equal(t, http.StatusOK, serveAs(router, "admin", "GET", "/domains").Code)
+}
+
+func TestAddresses_Owned(t *testing.T) {
+	var filtered string
+	router := newGuardedRouter(addressService{
+		getAll: func() (*[]session.Address, error) {
+			return &[]session.Address{
+				session.MustParseAddr("alice@mail.com"),
+				session.MustParseAddr("bob@mail.com"),
+			}, nil
+		},
+		getOwned: func(owner string) (*[]session.Address, error) {
+			filtered = owner
+			return &[]session.Address{session.MustParseAddr("alice@mail.com")}, nil
+		},
+	})
+
+	w := serveAs(router, "alice", "GET", "/addresses")
+	assert.Equal(t, `{"addresses":["alice@mail.com"]}`, w.Body.String())
+	assert.Equal(t, "alice", filtered)
+
+	w = serveAs(router, "admin", "GET", "/addresses")
+	assert.Equal(t, `{"addresses":["alice@mail.com","bob@mail.com"]}`, w.Body.String())
+}
+
+func TestNewAddress_Owner(t *testing.T) {
+	var owner string
+	router := newGuardedRouter(addressService{
+		create: func(o string, user string, domain string) (*session.Address, error) {
+			owner = o
+			return session.NewAddr(user, "mail.com")
+		},
+	})
+
+	w := serveAs(router, "alice", "POST", "/address/user/carol")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "alice", owner)
+}
+
+func TestOwns(t *testing.T) {
+	router := newGuardedRouter(addressService{
+		remove:     func(addr session.Address) error { return nil },
+		createHook: func(addr session.Address, id webhook.WebhookID) error { return nil },
+	})
+
+	// bob's address looks missing to alice.
+	assert.Equal(t, http.StatusNotFound, serveAs(router, "alice", "DELETE", "/address/bob@mail.com").Code)
+	assert.Equal(t, http.StatusOK, serveAs(router, "admin", "DELETE", "/address/bob@mail.com").Code)
+
+	// alice cannot link bob's webhook to her address.
+	url := "/address/alice@mail.com/webhook/271be94b-36d1-802e-d200-c1e0b85580b2"
+	assert.Equal(t, http.StatusNotFound, serveAs(router, "alice", "POST", url).Code)
+	assert.Equal(t, http.StatusCreated, serveAs(router, "admin", "POST", url).Code)
+
+	// malformed params are left to the handlers.
+	assert.Equal(t, http.StatusBadRequest, serveAs(router, "alice", "DELETE", "/address/invalid").Code)
+}
+
+func TestNewWebhook_OtherID(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "mtw.db") + "?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	SetRoutes(router, db, "mail.com", "", slog.Default())
+	issue := func(owner string) string {
+		_, secret, err := dbtoken.Create(db).One(owner, owner, []string{string(token.Write)}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+	alice, bob := issue("alice"), issue("bob")
+	serve := func(secret string, method string, url string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+secret)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(alice, "POST", "/webhook", `{"endpoint":"https://alice.local/hook","method":"GET"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created struct{ ID string }
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// bob cannot take over alice's webhook by posting its ID.
+	w = serve(bob, "POST", "/webhook", `{"id":"`+created.ID+`","endpoint":"https://bob.local/hook","method":"GET"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(alice, "GET", "/webhook/"+created.ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "https://alice.local/hook")
+	assert.Equal(t, http.StatusNotFound, serve(bob, "GET", "/webhook/"+created.ID, "").Code)
+}
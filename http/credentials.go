@@ -0,0 +1,83 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/database/credential"
+)
+
+type credentialService struct {
+	create func(username string, password string) (string, error)
+	getAll func() (*[]string, error)
+	remove func(username string) error
+}
+
+type credentialRoute struct {
+	credentialService
+	Logger
+}
+
+type credentialJson struct {
+	Password string `json:"password"` // at most credential.MaxPasswordBytes, which bcrypt counts in bytes.
+}
+
+func (r credentialRoute) register(e gin.IRoutes) {
+	e.GET("/credentials", r.all)
+	e.POST("/credential/:username", r.new)
+	e.DELETE("/credential/:username", r.delete)
+}
+
+func (r credentialRoute) all(c *gin.Context) {
+	users, err := r.getAll()
+	if err != nil {
+		r.Logger.Error("All", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"credentials": users})
+}
+
+// new registers the user for SMTP AUTH.
+// A random password is generated unless given, and is returned only once.
+func (r credentialRoute) new(c *gin.Context) {
+	var form credentialJson
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if len(form.Password) > credential.MaxPasswordBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": credential.ErrPasswordTooLong.Error()})
+		return
+	}
+	username := c.Param("username")
+	password, err := r.create(username, form.Password)
+	if errors.Is(err, credential.ErrEmptyUsername) || errors.Is(err, credential.ErrPasswordTooLong) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("new", "error", err, "username", username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"username": username, "password": password})
+}
+
+func (r credentialRoute) delete(c *gin.Context) {
+	err := r.remove(c.Param("username"))
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("delete", "error", err, "username", c.Param("username"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
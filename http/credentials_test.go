@@ -0,0 +1,91 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+func newCredentialsRoute(s credentialService) credentialRoute {
+	return credentialRoute{
+		credentialService: s,
+		Logger:            slog.Default(),
+	}
+}
+
+func Test_POST_Credential(t *testing.T) {
+	router := gin.Default()
+	newCredentialsRoute(credentialService{
+		create: func(username string, password string) (string, error) {
+			assert.Equal(t, "", password)
+			return "generated", nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/credential/service", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"password":"generated","username":"service"}`, w.Body.String())
+}
+
+func Test_POST_Credential_WithPassword(t *testing.T) {
+	router := gin.Default()
+	newCredentialsRoute(credentialService{
+		create: func(username string, password string) (string, error) {
+			return password, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/credential/service", strings.NewReader(`{"password":"secret"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"password":"secret","username":"service"}`, w.Body.String())
+}
+
+func Test_POST_Credential_TooLong(t *testing.T) {
+	router := gin.Default()
+	newCredentialsRoute(credentialService{}).register(router)
+
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{"ascii", strings.Repeat("a", 73)},
+		{"multibyte", strings.Repeat("あ", 25)}, // 25 runes in 75 bytes.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			body := `{"password":"` + tt.password + `"}`
+			req, _ := http.NewRequest("POST", "/credential/service", strings.NewReader(body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func Test_DELETE_Credential_NotFound(t *testing.T) {
+	router := gin.Default()
+	newCredentialsRoute(credentialService{
+		remove: func(username string) error {
+			return database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/credential/service", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
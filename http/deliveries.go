@@ -0,0 +1,137 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database/delivery"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+type deliveryService struct {
+	byWebhook     func(id webhook.WebhookID, q delivery.Query) (*[]webhook.Delivery, error)
+	byTransaction func(id uuid.UUID, q delivery.Query) (*[]webhook.Delivery, error)
+}
+
+type deliveryRoute struct {
+	deliveryService
+	Logger
+}
+
+type deliveryJson struct {
+	ID            string    `json:"id"`
+	TransactionID string    `json:"transaction_id"`
+	WebhookID     string    `json:"webhook_id"`
+	Address       string    `json:"address"`
+	BodyHash      string    `json:"body_hash"`
+	StatusCode    int       `json:"status_code"`
+	Response      string    `json:"response"`
+	LatencyMs     int64     `json:"latency_ms"`
+	Error         string    `json:"error"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func fromDelivery(d webhook.Delivery) deliveryJson {
+	return deliveryJson{
+		ID:            d.ID.String(),
+		TransactionID: d.TransactionID.String(),
+		WebhookID:     d.WebhookID.String(),
+		Address:       d.Address,
+		BodyHash:      d.BodyHash,
+		StatusCode:    d.StatusCode,
+		Response:      d.Response,
+		LatencyMs:     d.Latency.Milliseconds(),
+		Error:         d.Error,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+type deliveryQuery struct {
+	WebhookID string    `form:"webhook_id" binding:"omitempty,uuid"`
+	Address   string    `form:"address"`
+	Status    string    `form:"status" binding:"omitempty,oneof=success failure"`
+	Since     time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until     time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit     int       `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset    int       `form:"offset" binding:"omitempty,min=0"`
+}
+
+func (f deliveryQuery) into() delivery.Query {
+	q := delivery.Query{
+		Address: f.Address,
+		Since:   f.Since,
+		Until:   f.Until,
+		Limit:   f.Limit,
+		Offset:  f.Offset,
+	}
+	if f.WebhookID != "" {
+		id := uuid.MustParse(f.WebhookID)
+		q.WebhookID = &id
+	}
+	if f.Status != "" {
+		failed := f.Status == "failure"
+		q.Failed = &failed
+	}
+	return q
+}
+
+func (r deliveryRoute) register(e gin.IRoutes) {
+	e.GET("/webhook/:id/deliveries", r.ofWebhook)
+	e.GET("/deliveries/:transaction_id", r.ofTransaction)
+}
+
+func (r deliveryRoute) ofWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var form deliveryQuery
+	if err := c.ShouldBindQuery(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	deliveries, err := r.byWebhook(webhook.WebhookID(id), form.into())
+	if err != nil {
+		r.Logger.Error("ofWebhook", "error", err, "id", id.String())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	r.respond(c, *deliveries)
+}
+
+// ofTransaction is only for admins since a Transaction may be delivered
+// to the Webhooks of several owners.
+func (r deliveryRoute) ofTransaction(c *gin.Context) {
+	if _, restricted := filterOf(c); restricted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "token requires the admin scope"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("transaction_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var form deliveryQuery
+	if err := c.ShouldBindQuery(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	deliveries, err := r.byTransaction(id, form.into())
+	if err != nil {
+		r.Logger.Error("ofTransaction", "error", err, "id", id.String())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	r.respond(c, *deliveries)
+}
+
+func (r deliveryRoute) respond(c *gin.Context, deliveries []webhook.Delivery) {
+	res := make([]deliveryJson, len(deliveries))
+	for i, d := range deliveries {
+		res[i] = fromDelivery(d)
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": res})
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database/delivery"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+func newDeliveriesRoute(s deliveryService) deliveryRoute {
+	return deliveryRoute{
+		deliveryService: s,
+		Logger:          slog.Default(),
+	}
+}
+
+func testDelivery() webhook.Delivery {
+	return webhook.Delivery{
+		ID:            uuid.MustParse("0f0ba6c1-4d5c-4b1a-9a51-0d3f4b8e0d9e"),
+		TransactionID: uuid.MustParse("9b2e2f1a-7a43-4f5e-8a33-2b6d1d1a8c11"),
+		WebhookID:     webhook.WebhookID(uuid.MustParse("271be94b-36d1-802e-d200-c1e0b85580b2")),
+		Address:       "alice@mail.com",
+		BodyHash:      "hash",
+		StatusCode:    200,
+		Response:      "ok",
+		Latency:       time.Millisecond * 12,
+		CreatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func Test_GET_WebhookDeliveries(t *testing.T) {
+	var query delivery.Query
+	router := gin.Default()
+	newDeliveriesRoute(deliveryService{
+		byWebhook: func(_ webhook.WebhookID, q delivery.Query) (*[]webhook.Delivery, error) {
+			query = q
+			return &[]webhook.Delivery{testDelivery()}, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"GET",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2/deliveries?status=failure&limit=10&offset=20",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"deliveries":[{"id":"0f0ba6c1-4d5c-4b1a-9a51-0d3f4b8e0d9e","transaction_id":"9b2e2f1a-7a43-4f5e-8a33-2b6d1d1a8c11","webhook_id":"271be94b-36d1-802e-d200-c1e0b85580b2","address":"alice@mail.com","body_hash":"hash","status_code":200,"response":"ok","latency_ms":12,"error":"","created_at":"2024-01-01T00:00:00Z"}]}`, w.Body.String())
+	assert.True(t, *query.Failed)
+	assert.Equal(t, 10, query.Limit)
+	assert.Equal(t, 20, query.Offset)
+}
+
+func Test_GET_WebhookDeliveries_BadQuery(t *testing.T) {
+	router := gin.Default()
+	newDeliveriesRoute(deliveryService{}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"GET",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2/deliveries?status=unknown",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GET_TransactionDeliveries(t *testing.T) {
+	router := gin.Default()
+	newDeliveriesRoute(deliveryService{
+		byTransaction: func(id uuid.UUID, _ delivery.Query) (*[]webhook.Delivery, error) {
+			assert.Equal(t, "9b2e2f1a-7a43-4f5e-8a33-2b6d1d1a8c11", id.String())
+			return &[]webhook.Delivery{}, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"GET",
+		"/deliveries/9b2e2f1a-7a43-4f5e-8a33-2b6d1d1a8c11",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"deliveries":[]}`, w.Body.String())
+}
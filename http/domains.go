@@ -0,0 +1,64 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+type domainService struct {
+	create func(domain string) (string, error)
+	getAll func() (*[]string, error)
+	remove func(domain string) error
+}
+
+type domainRoute struct {
+	domainService
+	Logger
+}
+
+func (r domainRoute) register(e gin.IRoutes) {
+	e.GET("/domains", r.all)
+	e.POST("/domain/:domain", r.new)
+	e.DELETE("/domain/:domain", r.delete)
+}
+
+func (r domainRoute) all(c *gin.Context) {
+	domains, err := r.getAll()
+	if err != nil {
+		r.Logger.Error("All", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"domains": domains})
+}
+
+func (r domainRoute) new(c *gin.Context) {
+	domain, err := r.create(c.Param("domain"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"domain": domain})
+}
+
+// delete removes the domain. Domains that still have Addresses are kept.
+func (r domainRoute) delete(c *gin.Context) {
+	err := r.remove(c.Param("domain"))
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, database.ErrInUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("delete", "error", err, "domain", c.Param("domain"))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
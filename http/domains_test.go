@@ -0,0 +1,82 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+)
+
+func newDomainsRoute(s domainService) domainRoute {
+	return domainRoute{
+		domainService: s,
+		Logger:        slog.Default(),
+	}
+}
+
+func Test_GET_Domains(t *testing.T) {
+	router := gin.Default()
+	newDomainsRoute(domainService{
+		getAll: func() (*[]string, error) {
+			return &[]string{"example.com", "mail.com"}, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/domains", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"domains":["example.com","mail.com"]}`, w.Body.String())
+}
+
+func Test_POST_Domain(t *testing.T) {
+	router := gin.Default()
+	newDomainsRoute(domainService{
+		create: func(domain string) (string, error) {
+			return domain, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/domain/example.com", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"domain":"example.com"}`, w.Body.String())
+}
+
+func Test_POST_Domain_BadRequest(t *testing.T) {
+	router := gin.Default()
+	newDomainsRoute(domainService{
+		create: func(domain string) (string, error) {
+			return "", errors.New("invalid")
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/domain/invalid", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_DELETE_Domain_InUse(t *testing.T) {
+	router := gin.Default()
+	newDomainsRoute(domainService{
+		remove: func(domain string) error {
+			return database.ErrInUse
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/domain/example.com", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+// maxPreviewLength limits the size of messages to preview.
+const maxPreviewLength = 10 << 20
+
+// previewJson is a request to preview a Webhook not saved yet.
+type previewJson struct {
+	Webhook webhookJson `json:"webhook" binding:"required"`
+	Message string      `json:"message" binding:"required"` // a raw RFC 5322 message.
+	From    string      `json:"from"`                       // the envelope sender. Defaults to the From header.
+	To      string      `json:"to"`                         // the envelope recipient. Defaults to the To header.
+}
+
+// requestJson is a request a Webhook would send.
+type requestJson struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers"`
+	Body    string              `json:"body"`
+}
+
+// preview renders the request the Webhook would send for the message in the body, without sending it.
+// The envelope defaults to the From and To headers, and can be given by `from` and `to` queries.
+func (w webhookRoute) preview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPreviewLength))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hook, err := w.find(webhook.WebhookID(id))
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		w.Logger.Error("preview", "error", err, "id", id.String())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	w.render(c, *hook, raw, c.Query("from"), c.Query("to"))
+}
+
+// previewBlueprint renders the request a Webhook not saved yet would send, without sending it.
+func (w webhookRoute) previewBlueprint(c *gin.Context) {
+	var form previewJson
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bp, err := form.Webhook.into()
+	if invalidBlueprint(c, err) {
+		return
+	}
+	hook, err := w.build(*bp)
+	if invalidBlueprint(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w.render(c, *hook, []byte(form.Message), form.From, form.To)
+}
+
+func (w webhookRoute) render(c *gin.Context, hook webhook.Webhook, raw []byte, from string, to string) {
+	trans, err := previewTransaction(raw, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, err := hook.PrepareRequest(*trans)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	body := []byte{}
+	if req.Body != nil {
+		if body, err = io.ReadAll(req.Body); err != nil {
+			w.Logger.Error("preview", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	// The secret is write-only, so the preview never hands out a signature for a body the caller chose.
+	req.Header.Del(webhook.HeaderSignature)
+	req.Header.Del(webhook.HeaderTimestamp)
+	c.JSON(http.StatusOK, requestJson{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: req.Header,
+		Body:    string(body),
+	})
+}
+
+// previewTransaction parses the raw message into a Transaction.
+// Empty `from` and `to` are taken from the headers.
+func previewTransaction(raw []byte, from string, to string) (*session.Transaction, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("the message is not a mail: %w", err)
+	}
+	if from == "" {
+		from = msg.Header.Get("From")
+	}
+	if to == "" {
+		if list, err := msg.Header.AddressList("To"); err == nil && len(list) > 0 {
+			to = list[0].String()
+		}
+	}
+	sender, err := session.ParseAddr(from)
+	if err != nil {
+		return nil, fmt.Errorf("sender: %w", err)
+	}
+	rcpt, err := session.ParseAddr(to)
+	if err != nil {
+		return nil, fmt.Errorf("rcpt: %w", err)
+	}
+	return session.NewTransaction(uuid.New(), *sender, *rcpt, bytes.NewReader(raw))
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+const previewMail = "From: alice <alice@mail.com>\r\nTo: bob <bob@mail.com>\r\nSubject: hello\r\n\r\nhi \"bob\"\r\n"
+
+func Test_POST_Preview(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		find: func(_ webhook.WebhookID) (*webhook.Webhook, error) {
+			return webhook.FromBlueprint(webhook.Blueprint{
+				Endpoint:    "http://endpoint.com",
+				Method:      "POST",
+				Schema:      `{"text":{{json .Text}},"to":"{{.RcptAddress}}"}`,
+				SchemaMode:  webhook.SchemaModeText,
+				ContentType: webhook.ContentTypeJson,
+			})
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2/preview?to=carol@mail.com",
+		strings.NewReader(previewMail),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res requestJson
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, "POST", res.Method)
+	assert.Equal(t, "http://endpoint.com", res.URL)
+	assert.Equal(t, []string{"application/json"}, res.Headers["Content-Type"])
+	assert.Equal(t, `{"text":"hi \"bob\"\r\n","to":"carol@mail.com"}`, res.Body)
+}
+
+func Test_POST_Preview_Unsigned(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		find: func(_ webhook.WebhookID) (*webhook.Webhook, error) {
+			return webhook.FromBlueprint(webhook.Blueprint{
+				Endpoint:    "http://endpoint.com",
+				Method:      "POST",
+				Schema:      `{"text":{{json .Text}}}`,
+				SchemaMode:  webhook.SchemaModeText,
+				ContentType: webhook.ContentTypeJson,
+				Secret:      "secret",
+			})
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2/preview",
+		strings.NewReader(previewMail),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res requestJson
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.NotContains(t, res.Headers, webhook.HeaderSignature)
+	assert.NotContains(t, res.Headers, webhook.HeaderTimestamp)
+}
+
+func Test_POST_Preview_NotFound(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		find: func(_ webhook.WebhookID) (*webhook.Webhook, error) {
+			return nil, database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2/preview",
+		strings.NewReader(previewMail),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_POST_PreviewBlueprint(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		build: func(bp webhook.Blueprint) (*webhook.Webhook, error) {
+			return webhook.FromBlueprint(bp)
+		},
+	}).register(router)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{
+			"ok",
+			`{"webhook":{"endpoint":"http://endpoint.com","method":"GET"},"message":"From: alice@mail.com\nTo: bob@mail.com\n\nhi"}`,
+			http.StatusOK,
+		},
+		{
+			"invalid json",
+			`{"webhook":{"endpoint":"http://endpoint.com","method":"POST","schema":"{{.Text}}","schema_mode":"text","content_type":"application/json"},"message":"From: alice@mail.com\nTo: bob@mail.com\n\nhi"}`,
+			http.StatusUnprocessableEntity,
+		},
+		{
+			"malformed schema",
+			`{"webhook":{"endpoint":"http://endpoint.com","schema":"{{.Text"},"message":"From: alice@mail.com\nTo: bob@mail.com\n\nhi"}`,
+			http.StatusBadRequest,
+		},
+		{
+			"no sender",
+			`{"webhook":{"endpoint":"http://endpoint.com"},"message":"To: bob@mail.com\n\nhi"}`,
+			http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/webhook/preview", strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+		})
+	}
+}
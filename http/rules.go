@@ -0,0 +1,121 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/database/rule"
+	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/spam"
+)
+
+type ruleService struct {
+	create func(addr session.Address, action string, pattern string) (*rule.Rule, error)
+	find   func(addr session.Address) (*[]rule.Rule, error)
+	remove func(addr session.Address, id uuid.UUID) error
+}
+
+type ruleRoute struct {
+	ruleService
+	Logger
+}
+
+type ruleJson struct {
+	ID        string    `json:"id"`
+	Action    string    `json:"action"`
+	Pattern   string    `json:"pattern"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func fromRule(r rule.Rule) ruleJson {
+	return ruleJson{
+		ID:        r.ID.String(),
+		Action:    string(r.Action),
+		Pattern:   r.Pattern,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+type ruleForm struct {
+	Action  string `json:"action" binding:"required,oneof=allow deny"`
+	Pattern string `json:"pattern" binding:"required"`
+}
+
+func (r ruleRoute) register(e gin.IRoutes) {
+	e.GET("/address/:addr/rules", r.all)
+	e.POST("/address/:addr/rules", r.new)
+	e.DELETE("/address/:addr/rules/:id", r.delete)
+}
+
+func (r ruleRoute) all(c *gin.Context) {
+	addr, err := session.ParseAddr(c.Param("addr"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rules, err := r.find(*addr)
+	if err != nil {
+		r.Logger.Error("rules", "error", err, "addr", addr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	res := make([]ruleJson, len(*rules))
+	for i, rule := range *rules {
+		res[i] = fromRule(rule)
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": res})
+}
+
+// new adds a sender rule to the Address.
+// Once an `allow` rule is added, only the senders matching an `allow` rule are accepted.
+func (r ruleRoute) new(c *gin.Context) {
+	addr, err := session.ParseAddr(c.Param("addr"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var form ruleForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule, err := r.create(*addr, form.Action, form.Pattern)
+	if errors.Is(err, spam.ErrInvalidRule) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("new", "error", err, "addr", addr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, fromRule(*rule))
+}
+
+func (r ruleRoute) delete(c *gin.Context) {
+	addr, err := session.ParseAddr(c.Param("addr"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = r.remove(*addr, id)
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("delete", "error", err, "addr", addr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
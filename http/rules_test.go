@@ -0,0 +1,76 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/database/rule"
+	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/spam"
+)
+
+func newRulesRoute(s ruleService) ruleRoute {
+	return ruleRoute{
+		ruleService: s,
+		Logger:      slog.Default(),
+	}
+}
+
+func Test_POST_Rule(t *testing.T) {
+	router := gin.Default()
+	newRulesRoute(ruleService{
+		create: func(addr session.Address, action string, pattern string) (*rule.Rule, error) {
+			assert.Equal(t, "alice@mail.com", addr.String())
+			r, err := spam.NewRule(action, pattern)
+			if err != nil {
+				return nil, err
+			}
+			return &rule.Rule{
+				ID:        uuid.MustParse("0f0ba6c1-4d5c-4b1a-9a51-0d3f4b8e0d9e"),
+				Rule:      *r,
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			}, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/address/alice@mail.com/rules", strings.NewReader(`{"action":"allow","pattern":"github.com"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":"0f0ba6c1-4d5c-4b1a-9a51-0d3f4b8e0d9e","action":"allow","pattern":"*@github.com","created_at":"2024-01-01T00:00:00Z"}`, w.Body.String())
+}
+
+func Test_POST_Rule_BadRequest(t *testing.T) {
+	router := gin.Default()
+	newRulesRoute(ruleService{}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/address/alice@mail.com/rules", strings.NewReader(`{"action":"block","pattern":"github.com"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_DELETE_Rule_NotFound(t *testing.T) {
+	router := gin.Default()
+	newRulesRoute(ruleService{
+		remove: func(addr session.Address, id uuid.UUID) error {
+			return database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/address/alice@mail.com/rules/0f0ba6c1-4d5c-4b1a-9a51-0d3f4b8e0d9e", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -1,12 +1,22 @@
 package http
 
 import (
+	"crypto/subtle"
 	"database/sql"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/zen-en-tonal/mtw/database/address"
+	"github.com/zen-en-tonal/mtw/database/credential"
+	"github.com/zen-en-tonal/mtw/database/delivery"
+	"github.com/zen-en-tonal/mtw/database/domain"
+	"github.com/zen-en-tonal/mtw/database/outbox"
+	"github.com/zen-en-tonal/mtw/database/rule"
+	dbtoken "github.com/zen-en-tonal/mtw/database/token"
 	"github.com/zen-en-tonal/mtw/database/webhook"
 	"github.com/zen-en-tonal/mtw/session"
+	"github.com/zen-en-tonal/mtw/token"
 	w "github.com/zen-en-tonal/mtw/webhook"
 )
 
@@ -14,31 +24,143 @@ type Logger interface {
 	Error(msg string, args ...any)
 }
 
-func SetRoutes(r *gin.Engine, db *sql.DB, domain string, logger Logger) {
+// SetRoutes registers the routes of the API guarded by API tokens.
+// Addresses are created on `defaultDomain` unless a domain is specified.
+// A non-empty `bootstrap` is accepted as an admin token with no owner.
+func SetRoutes(r *gin.Engine, db *sql.DB, defaultDomain string, bootstrap string, logger Logger) {
+	orDefault := func(d string) string {
+		if d == "" {
+			return defaultDomain
+		}
+		return d
+	}
 	addrRouter := addressRoute{
 		addressService{
-			create:       address.Create(db, domain).WithUser,
-			createRandom: address.Create(db, domain).WithRandom,
-			getAll:       address.Find(db).All,
-			getHooks:     webhook.NewFind(db).ByAddr,
+			create: func(owner string, user string, d string) (*session.Address, error) {
+				return address.Create(db, orDefault(d)).OwnedBy(owner).WithUser(user)
+			},
+			createRandom: func(owner string, d string) (*session.Address, error) {
+				return address.Create(db, orDefault(d)).OwnedBy(owner).WithRandom()
+			},
+			getAll:   address.Find(db).All,
+			getOwned: address.Find(db).OwnedBy,
+			getHooks: webhook.NewFind(db).ByAddr,
 			createHook: func(addr session.Address, id w.WebhookID) error {
 				return webhook.NewRegistry(db, addr).Create(id)
 			},
 			removeHook: func(addr session.Address, id w.WebhookID) error {
 				return webhook.NewRegistry(db, addr).Remove(id)
 			},
+			routeHook: func(addr session.Address, id w.WebhookID, c w.Condition) error {
+				return webhook.NewRegistry(db, addr).Route(id, c)
+			},
+			getRoute: func(addr session.Address, id w.WebhookID) (*w.Condition, error) {
+				link, err := webhook.NewFind(db).Link(addr, id)
+				if err != nil {
+					return nil, err
+				}
+				return &link.Condition, nil
+			},
+			remove: address.Delete(db).One,
 		},
 		logger,
 	}
 	webhookRouter := webhookRoute{
 		webhookService{
-			create: webhook.NewCreate(db).FromBlueprint,
+			create: func(owner string, bp w.Blueprint) (*w.Webhook, error) {
+				return webhook.NewCreate(db).OwnedBy(owner).FromBlueprint(bp)
+			},
 			find:   webhook.NewFind(db).ByID,
 			all:    webhook.NewFind(db).All,
+			owned:  webhook.NewFind(db).OwnedBy,
+			rotate: webhook.NewCreate(db).RotateSecret,
+			update: webhook.NewCreate(db).Update,
+			stored: webhook.NewFind(db).Blueprint,
+			remove: webhook.NewDelete(db).ByID,
+			build: func(bp w.Blueprint) (*w.Webhook, error) {
+				if err := bp.Validate(); err != nil {
+					return nil, err
+				}
+				return w.FromBlueprint(bp)
+			},
+		},
+		logger,
+	}
+	deliveryRouter := deliveryRoute{
+		deliveryService{
+			byWebhook:     delivery.NewFind(db).ByWebhook,
+			byTransaction: delivery.NewFind(db).ByTransaction,
+		},
+		logger,
+	}
+	transactionRouter := transactionRoute{
+		transactionService{
+			replayAll: outbox.NewReplay(db).All,
+			replayTo:  outbox.NewReplay(db).To,
+		},
+		logger,
+	}
+	domainRouter := domainRoute{
+		domainService{
+			create: domain.Create(db).One,
+			getAll: domain.Find(db).All,
+			remove: domain.Delete(db).One,
 		},
 		logger,
 	}
+	credentialRouter := credentialRoute{
+		credentialService{
+			create: credential.Create(db).One,
+			getAll: credential.Find(db).All,
+			remove: credential.Delete(db).One,
+		},
+		logger,
+	}
+	ruleRouter := ruleRoute{
+		ruleService{
+			create: func(addr session.Address, action string, pattern string) (*rule.Rule, error) {
+				return rule.Create(db, addr).One(action, pattern)
+			},
+			find: rule.Find(db).ByAddr,
+			remove: func(addr session.Address, id uuid.UUID) error {
+				return rule.Delete(db, addr).One(id)
+			},
+		},
+		logger,
+	}
+	tokenRouter := tokenRoute{
+		tokenService{
+			create: dbtoken.Create(db).One,
+			getAll: dbtoken.Find(db).All,
+			remove: dbtoken.Delete(db).One,
+		},
+		logger,
+	}
+	guard := authGuard{
+		authService{
+			verify: func(secret string) (*token.Token, error) {
+				if bootstrap != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(bootstrap)) == 1 {
+					return &token.Token{Name: "bootstrap", Scopes: []token.Scope{token.Admin}, CreatedAt: time.Now()}, nil
+				}
+				return dbtoken.Find(db).Authenticate(secret)
+			},
+			addressOwner: address.Find(db).Owner,
+			webhookOwner: webhook.NewFind(db).Owner,
+		},
+		logger,
+	}
+
+	api := r.Group("", guard.authenticate)
+	admin := api.Group("", require(token.Admin))
+	addresses := api.Group("", guard.ownsAddress("addr"), guard.ownsWebhook("whid"))
+	webhooks := api.Group("", guard.ownsWebhook("id"))
 
-	addrRouter.register(r)
-	webhookRouter.register(r)
+	addrRouter.register(addresses)
+	ruleRouter.register(addresses)
+	webhookRouter.register(webhooks)
+	deliveryRouter.register(webhooks)
+	transactionRouter.register(admin)
+	domainRouter.register(admin)
+	credentialRouter.register(admin)
+	tokenRouter.register(admin)
 }
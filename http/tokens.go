@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/token"
+)
+
+type tokenService struct {
+	create func(name string, owner string, scopes []string, expiresAt *time.Time) (*token.Token, string, error)
+	getAll func() (*[]token.Token, error)
+	remove func(id uuid.UUID) error
+}
+
+type tokenRoute struct {
+	tokenService
+	Logger
+}
+
+type tokenJson struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Owner     string     `json:"owner"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	Token     string     `json:"token,omitempty"` // only when issued.
+}
+
+func fromToken(t token.Token) tokenJson {
+	scopes := make([]string, len(t.Scopes))
+	for i, s := range t.Scopes {
+		scopes[i] = string(s)
+	}
+	return tokenJson{
+		ID:        t.ID.String(),
+		Name:      t.Name,
+		Owner:     t.Owner,
+		Scopes:    scopes,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+type newTokenJson struct {
+	Name      string     `json:"name" binding:"required"`
+	Owner     string     `json:"owner"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r tokenRoute) register(e gin.IRoutes) {
+	e.GET("/tokens", r.all)
+	e.POST("/token", r.new)
+	e.DELETE("/token/:id", r.delete)
+}
+
+func (r tokenRoute) all(c *gin.Context) {
+	tokens, err := r.getAll()
+	if err != nil {
+		r.Logger.Error("All", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	res := make([]tokenJson, len(*tokens))
+	for i, t := range *tokens {
+		res[i] = fromToken(t)
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": res})
+}
+
+// new issues a token. The secret is returned only once.
+func (r tokenRoute) new(c *gin.Context) {
+	var form newTokenJson
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t, secret, err := r.create(form.Name, form.Owner, form.Scopes, form.ExpiresAt)
+	if errors.Is(err, token.ErrInvalidScope) || errors.Is(err, token.ErrEmptyOwner) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("new", "error", err, "name", form.Name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	res := fromToken(*t)
+	res.Token = secret
+	c.JSON(http.StatusCreated, res)
+}
+
+func (r tokenRoute) delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = r.remove(id)
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("delete", "error", err, "id", id.String())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/token"
+)
+
+func newTokensRoute(s tokenService) tokenRoute {
+	return tokenRoute{
+		tokenService: s,
+		Logger:       slog.Default(),
+	}
+}
+
+func Test_POST_Token(t *testing.T) {
+	var expires *time.Time
+	router := gin.Default()
+	newTokensRoute(tokenService{
+		create: func(name string, owner string, scopes []string, expiresAt *time.Time) (*token.Token, string, error) {
+			expires = expiresAt
+			parsed, err := token.ParseScopes(scopes)
+			if err != nil {
+				return nil, "", err
+			}
+			return &token.Token{
+				ID:        uuid.MustParse("271be94b-36d1-802e-d200-c1e0b85580b2"),
+				Name:      name,
+				Owner:     owner,
+				Scopes:    parsed,
+				ExpiresAt: expiresAt,
+				CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			}, "mtw_secret", nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/token",
+		strings.NewReader(`{"name":"ci","owner":"alice","scopes":["read"],"expires_at":"2025-01-01T00:00:00Z"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":"271be94b-36d1-802e-d200-c1e0b85580b2","name":"ci","owner":"alice","scopes":["read"],"expires_at":"2025-01-01T00:00:00Z","created_at":"2024-05-01T00:00:00Z","token":"mtw_secret"}`, w.Body.String())
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *expires)
+}
+
+func Test_POST_Token_InvalidScope(t *testing.T) {
+	router := gin.Default()
+	newTokensRoute(tokenService{
+		create: func(name string, owner string, scopes []string, expiresAt *time.Time) (*token.Token, string, error) {
+			_, err := token.ParseScopes(scopes)
+			return nil, "", err
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/token", strings.NewReader(`{"name":"ci","scopes":["root"]}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_POST_Token_EmptyOwner(t *testing.T) {
+	router := gin.Default()
+	newTokensRoute(tokenService{
+		create: func(name string, owner string, scopes []string, expiresAt *time.Time) (*token.Token, string, error) {
+			parsed, err := token.ParseScopes(scopes)
+			if err != nil {
+				return nil, "", err
+			}
+			return nil, "", token.Token{Owner: owner, Scopes: parsed}.Validate()
+		},
+	}).register(router)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no owner", `{"name":"ci","scopes":["write"]}`},
+		{"blank owner", `{"name":"ci","owner":" ","scopes":["read"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/token", strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), token.ErrEmptyOwner.Error())
+		})
+	}
+}
+
+func Test_GET_Tokens(t *testing.T) {
+	router := gin.Default()
+	newTokensRoute(tokenService{
+		getAll: func() (*[]token.Token, error) {
+			return &[]token.Token{{
+				ID:        uuid.MustParse("271be94b-36d1-802e-d200-c1e0b85580b2"),
+				Name:      "ci",
+				Scopes:    []token.Scope{token.Admin},
+				CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			}}, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tokens", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"tokens":[{"id":"271be94b-36d1-802e-d200-c1e0b85580b2","name":"ci","owner":"","scopes":["admin"],"expires_at":null,"created_at":"2024-05-01T00:00:00Z"}]}`, w.Body.String())
+}
+
+func Test_DELETE_Token_NotFound(t *testing.T) {
+	router := gin.Default()
+	newTokensRoute(tokenService{
+		remove: func(id uuid.UUID) error {
+			return database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/token/271be94b-36d1-802e-d200-c1e0b85580b2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+type transactionService struct {
+	replayAll func(id uuid.UUID) (*[]uuid.UUID, error)
+	replayTo  func(id uuid.UUID, webhookID webhook.WebhookID) (*[]uuid.UUID, error)
+}
+
+type transactionRoute struct {
+	transactionService
+	Logger
+}
+
+func (r transactionRoute) register(e gin.IRoutes) {
+	e.POST("/transactions/:id/replay", r.replay)
+}
+
+// replay queues a received mail again.
+// If `webhook_id` is given, only that Webhook receives it.
+func (r transactionRoute) replay(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var jobs *[]uuid.UUID
+	if whid := c.Query("webhook_id"); whid != "" {
+		hookID, perr := uuid.Parse(whid)
+		if perr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": perr.Error()})
+			return
+		}
+		jobs, err = r.replayTo(id, webhook.WebhookID(hookID))
+	} else {
+		jobs, err = r.replayAll(id)
+	}
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Logger.Error("replay", "error", err, "id", id.String())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := make([]string, len(*jobs))
+	for i, job := range *jobs {
+		ids[i] = job.String()
+	}
+	c.JSON(http.StatusAccepted, gin.H{"jobs": ids})
+}
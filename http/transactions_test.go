@@ -0,0 +1,80 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/database"
+	"github.com/zen-en-tonal/mtw/webhook"
+)
+
+func newTransactionsRoute(s transactionService) transactionRoute {
+	return transactionRoute{
+		transactionService: s,
+		Logger:             slog.Default(),
+	}
+}
+
+func Test_POST_Replay(t *testing.T) {
+	router := gin.Default()
+	newTransactionsRoute(transactionService{
+		replayAll: func(_ uuid.UUID) (*[]uuid.UUID, error) {
+			return &[]uuid.UUID{uuid.MustParse("0f0ba6c1-4d5c-4b1a-9a51-0d3f4b8e0d9e")}, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/transactions/9b2e2f1a-7a43-4f5e-8a33-2b6d1d1a8c11/replay",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, `{"jobs":["0f0ba6c1-4d5c-4b1a-9a51-0d3f4b8e0d9e"]}`, w.Body.String())
+}
+
+func Test_POST_Replay_Webhook(t *testing.T) {
+	router := gin.Default()
+	newTransactionsRoute(transactionService{
+		replayTo: func(_ uuid.UUID, id webhook.WebhookID) (*[]uuid.UUID, error) {
+			assert.Equal(t, "271be94b-36d1-802e-d200-c1e0b85580b2", id.String())
+			return &[]uuid.UUID{uuid.MustParse("0f0ba6c1-4d5c-4b1a-9a51-0d3f4b8e0d9e")}, nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/transactions/9b2e2f1a-7a43-4f5e-8a33-2b6d1d1a8c11/replay?webhook_id=271be94b-36d1-802e-d200-c1e0b85580b2",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+
+func Test_POST_Replay_NotFound(t *testing.T) {
+	router := gin.Default()
+	newTransactionsRoute(transactionService{
+		replayAll: func(_ uuid.UUID) (*[]uuid.UUID, error) {
+			return nil, database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/transactions/9b2e2f1a-7a43-4f5e-8a33-2b6d1d1a8c11/replay",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
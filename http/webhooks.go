@@ -11,9 +11,15 @@ import (
 )
 
 type webhookService struct {
-	create func(bp webhook.Blueprint) (*webhook.Webhook, error)
+	create func(owner string, bp webhook.Blueprint) (*webhook.Webhook, error)
 	find   func(id webhook.WebhookID) (*webhook.Webhook, error)
 	all    func() (*[]webhook.Webhook, error)
+	owned  func(owner string) (*[]webhook.Webhook, error)
+	rotate func(id webhook.WebhookID) (string, error)
+	update func(bp webhook.Blueprint) (*webhook.Webhook, error)
+	stored func(id webhook.WebhookID) (*webhook.Blueprint, error)
+	remove func(id webhook.WebhookID) error
+	build  func(bp webhook.Blueprint) (*webhook.Webhook, error) // builds a Webhook without saving.
 }
 
 type webhookRoute struct {
@@ -26,35 +32,103 @@ type webhookJson struct {
 	Endpoint    string `json:"endpoint" binding:"required"`
 	Auth        string `json:"auth"`
 	Schema      string `json:"schema"`
+	SchemaMode  string `json:"schema_mode,omitempty"`
 	Method      string `json:"method"`
 	ContentType string `json:"content_type"`
+	Secret      string `json:"secret,omitempty"` // write only.
+
+	Preset string            `json:"preset,omitempty"` // write only. fills in the fields omitted.
+	Vars   map[string]string `json:"vars,omitempty"`   // write only. overrides the variables of the preset.
 }
 
-func (f webhookJson) into() webhook.Blueprint {
-	return webhook.Blueprint{
+// into returns the Blueprint with the preset applied.
+//
+// # Errors
+//   - webhook.ValidationError if the preset is unknown or the vars are invalid.
+func (f webhookJson) into() (*webhook.Blueprint, error) {
+	bp := webhook.Blueprint{
 		ID:          f.ID,
 		Endpoint:    f.Endpoint,
 		Auth:        f.Auth,
 		Schema:      f.Schema,
+		SchemaMode:  f.SchemaMode,
 		Method:      f.Method,
 		ContentType: f.ContentType,
+		Secret:      f.Secret,
+	}
+	if f.Preset == "" {
+		if len(f.Vars) > 0 {
+			return nil, webhook.ValidationError{"vars": "requires a preset"}
+		}
+		return &bp, nil
+	}
+	preset, err := webhook.FindPreset(f.Preset)
+	if err != nil {
+		return nil, webhook.ValidationError{"preset": err.Error()}
+	}
+	return preset.Apply(bp, f.Vars)
+}
+
+// webhookPatchJson holds the fields to change. Omitted fields are kept.
+type webhookPatchJson struct {
+	Endpoint    *string `json:"endpoint"`
+	Auth        *string `json:"auth"`
+	Schema      *string `json:"schema"`
+	SchemaMode  *string `json:"schema_mode,omitempty"`
+	Method      *string `json:"method"`
+	ContentType *string `json:"content_type"`
+	Secret      *string `json:"secret"`
+}
+
+func (f webhookPatchJson) apply(bp *webhook.Blueprint) {
+	set := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
 	}
+	set(&bp.Endpoint, f.Endpoint)
+	set(&bp.Auth, f.Auth)
+	set(&bp.Schema, f.Schema)
+	set(&bp.SchemaMode, f.SchemaMode)
+	set(&bp.Method, f.Method)
+	set(&bp.ContentType, f.ContentType)
+	set(&bp.Secret, f.Secret)
 }
 
-func (r webhookRoute) register(e *gin.Engine) {
+func (r webhookRoute) register(e gin.IRoutes) {
 	e.POST("/webhook", r.new)
 	e.GET("/webhook/:id", r.findOne)
 	e.GET("/webhooks", r.findAll)
+	e.PUT("/webhook/:id", r.replace)
+	e.PATCH("/webhook/:id", r.patch)
+	e.DELETE("/webhook/:id", r.delete)
+	e.POST("/webhook/:id/secret", r.rotateSecret)
+	e.POST("/webhook/:id/preview", r.preview)
+	e.POST("/webhook/preview", r.previewBlueprint)
+	e.GET("/webhook/presets", r.presets)
 }
 
+// new creates a Webhook owned by the owner of the token.
+// Its ID is assigned by the server, and an ID in the body is refused.
 func (w webhookRoute) new(c *gin.Context) {
 	var form webhookJson
 	if err := c.ShouldBindJSON(&form); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if form.ID != "" {
+		invalidBlueprint(c, webhook.ValidationError{"id": "is assigned by the server"})
+		return
+	}
 
-	webhook, err := w.create(form.into())
+	bp, err := form.into()
+	if invalidBlueprint(c, err) {
+		return
+	}
+	webhook, err := w.create(ownerOf(c), *bp)
+	if invalidBlueprint(c, err) {
+		return
+	}
 	if err != nil {
 		w.Logger.Error("New", "error", err, "form", form)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -64,6 +138,101 @@ func (w webhookRoute) new(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"id": webhook.ID().String()})
 }
 
+// replace overwrites the Webhook. An omitted secret keeps the current one.
+func (w webhookRoute) replace(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var form webhookJson
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	form.ID = id.String()
+	bp, err := form.into()
+	if invalidBlueprint(c, err) {
+		return
+	}
+
+	w.save(c, *bp)
+}
+
+func (w webhookRoute) patch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var form webhookPatchJson
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bp, err := w.stored(webhook.WebhookID(id))
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		w.Logger.Error("patch", "error", err, "id", id.String())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	form.apply(bp)
+
+	w.save(c, *bp)
+}
+
+func (w webhookRoute) save(c *gin.Context, bp webhook.Blueprint) {
+	webhook, err := w.update(bp)
+	if invalidBlueprint(c, err) {
+		return
+	}
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		w.Logger.Error("save", "error", err, "id", bp.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": webhook.ID().String()})
+}
+
+// invalidBlueprint responds 400 with the invalid fields if err is a webhook.ValidationError.
+func invalidBlueprint(c *gin.Context, err error) bool {
+	var invalid webhook.ValidationError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": webhook.ErrInvalidBlueprint.Error(), "fields": invalid})
+	return true
+}
+
+// delete removes the Webhook and its links to Addresses.
+func (w webhookRoute) delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = w.remove(webhook.WebhookID(id))
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		w.Logger.Error("delete", "error", err, "id", id.String())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
 func (w webhookRoute) findOne(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -87,13 +256,19 @@ func (w webhookRoute) findOne(c *gin.Context) {
 		Endpoint:    bp.Endpoint,
 		Auth:        bp.Auth,
 		Schema:      bp.Schema,
+		SchemaMode:  bp.SchemaMode,
 		Method:      bp.Method,
 		ContentType: bp.ContentType,
 	})
 }
 
+// findAll returns the Webhooks of the owner, or every Webhook for admins.
 func (w webhookRoute) findAll(c *gin.Context) {
-	webhooks, err := w.all()
+	get := w.all
+	if owner, restricted := filterOf(c); restricted {
+		get = func() (*[]webhook.Webhook, error) { return w.owned(owner) }
+	}
+	webhooks, err := get()
 	if err != nil {
 		w.Logger.Error("findAll", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -108,9 +283,57 @@ func (w webhookRoute) findAll(c *gin.Context) {
 			Endpoint:    bp.Endpoint,
 			Auth:        bp.Auth,
 			Schema:      bp.Schema,
+			SchemaMode:  bp.SchemaMode,
 			Method:      bp.Method,
 			ContentType: bp.ContentType,
 		}
 	}
 	c.JSON(http.StatusOK, bps)
 }
+
+// rotateSecret replaces the signing secret with a random one and returns it.
+func (w webhookRoute) rotateSecret(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	secret, err := w.rotate(webhook.WebhookID(id))
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		w.Logger.Error("rotateSecret", "error", err, "id", id.String())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"secret": secret})
+}
+
+// presetJson describes a preset without its schema.
+type presetJson struct {
+	Name        string              `json:"name"`
+	Method      string              `json:"method"`
+	ContentType string              `json:"content_type"`
+	Vars        []webhook.PresetVar `json:"vars"`
+}
+
+// presets lists the built-in presets with their variables.
+func (w webhookRoute) presets(c *gin.Context) {
+	presets := webhook.Presets()
+	res := make([]presetJson, len(presets))
+	for i, p := range presets {
+		vars := p.Vars
+		if vars == nil {
+			vars = []webhook.PresetVar{}
+		}
+		res[i] = presetJson{
+			Name:        p.Name,
+			Method:      p.Method,
+			ContentType: p.ContentType,
+			Vars:        vars,
+		}
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -24,7 +24,8 @@ func newWebhooksRoute(s webhookService) webhookRoute {
 func Test_POST_Webhook(t *testing.T) {
 	router := gin.Default()
 	newWebhooksRoute(webhookService{
-		create: func(bp webhook.Blueprint) (*webhook.Webhook, error) {
+		create: func(owner string, bp webhook.Blueprint) (*webhook.Webhook, error) {
+			bp.ID = "271be94b-36d1-802e-d200-c1e0b85580b2"
 			return webhook.FromBlueprint(bp)
 		},
 	}).register(router)
@@ -33,12 +34,45 @@ func Test_POST_Webhook(t *testing.T) {
 	req, _ := http.NewRequest(
 		"POST",
 		"/webhook",
-		strings.NewReader(`{"ID":"271be94b-36d1-802e-d200-c1e0b85580b2","method":"GET","endpoint":"http://endpoint.com"}`),
+		strings.NewReader(`{"method":"GET","endpoint":"http://endpoint.com"}`),
 	)
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusCreated, w.Code)
 	assert.Equal(t, `{"id":"271be94b-36d1-802e-d200-c1e0b85580b2"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(
+		"POST",
+		"/webhook",
+		strings.NewReader(`{"id":"271be94b-36d1-802e-d200-c1e0b85580b2","method":"GET","endpoint":"http://endpoint.com"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_POST_Webhook_Invalid(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		create: func(owner string, bp webhook.Blueprint) (*webhook.Webhook, error) {
+			if err := bp.Validate(); err != nil {
+				return nil, err
+			}
+			return webhook.FromBlueprint(bp)
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/webhook",
+		strings.NewReader(`{"method":"SEND","endpoint":"ftp://endpoint.com","schema":"{{.Body}}","content_type":"text/plain"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"error":"invalid blueprint","fields":{"endpoint":"has to be an http or https URL","method":"\"SEND\" is not one of GET, HEAD, POST, PUT, PATCH and DELETE","schema":"session.Transaction has no field or method Body"}}`, w.Body.String())
 }
 
 func Test_GET_Webhook(t *testing.T) {
@@ -103,3 +137,205 @@ func Test_GET_AllWebhook(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, `[{"id":"271be94b-36d1-802e-d200-c1e0b85580b2","endpoint":"http://endpoint.com","auth":"","schema":"","method":"GET","content_type":""}]`, w.Body.String())
 }
+
+func Test_POST_WebhookSecret(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		rotate: func(_ webhook.WebhookID) (string, error) {
+			return "new-secret", nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2/secret",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"secret":"new-secret"}`, w.Body.String())
+}
+
+func Test_POST_WebhookSecret_NotFound(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		rotate: func(_ webhook.WebhookID) (string, error) {
+			return "", database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2/secret",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PUT_Webhook(t *testing.T) {
+	var saved webhook.Blueprint
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		update: func(bp webhook.Blueprint) (*webhook.Webhook, error) {
+			saved = bp
+			return webhook.FromBlueprint(bp)
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"PUT",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		strings.NewReader(`{"method":"GET","endpoint":"http://endpoint.com"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "271be94b-36d1-802e-d200-c1e0b85580b2", saved.ID)
+	assert.Equal(t, "http://endpoint.com", saved.Endpoint)
+}
+
+func Test_PUT_Webhook_NotFound(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		update: func(_ webhook.Blueprint) (*webhook.Webhook, error) {
+			return nil, database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"PUT",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		strings.NewReader(`{"method":"GET","endpoint":"http://endpoint.com"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PATCH_Webhook(t *testing.T) {
+	var saved webhook.Blueprint
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		stored: func(id webhook.WebhookID) (*webhook.Blueprint, error) {
+			return &webhook.Blueprint{
+				ID:       id.String(),
+				Endpoint: "http://endpoint.com",
+				Method:   "GET",
+				Auth:     "token",
+			}, nil
+		},
+		update: func(bp webhook.Blueprint) (*webhook.Webhook, error) {
+			saved = bp
+			return webhook.FromBlueprint(bp)
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"PATCH",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		strings.NewReader(`{"endpoint":"http://other.com"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "http://other.com", saved.Endpoint)
+	assert.Equal(t, "GET", saved.Method)
+	assert.Equal(t, "token", saved.Auth)
+}
+
+func Test_DELETE_Webhook(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		remove: func(_ webhook.WebhookID) error {
+			return nil
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"DELETE",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func Test_DELETE_Webhook_NotFound(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		remove: func(_ webhook.WebhookID) error {
+			return database.ErrNotFound
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"DELETE",
+		"/webhook/271be94b-36d1-802e-d200-c1e0b85580b2",
+		nil,
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_POST_Webhook_Preset(t *testing.T) {
+	var created webhook.Blueprint
+	router := gin.Default()
+	newWebhooksRoute(webhookService{
+		create: func(owner string, bp webhook.Blueprint) (*webhook.Webhook, error) {
+			created = bp
+			if err := bp.Validate(); err != nil {
+				return nil, err
+			}
+			return webhook.FromBlueprint(bp)
+		},
+	}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(
+		"POST",
+		"/webhook",
+		strings.NewReader(`{"endpoint":"https://hooks.slack.com/services/xxxx","preset":"slack","vars":{"username":"mtw"}}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "POST", created.Method)
+	assert.Equal(t, webhook.ContentTypeJson, created.ContentType)
+	assert.Equal(t, webhook.SchemaModeText, created.SchemaMode)
+	assert.Contains(t, created.Schema, `"username": "mtw"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(
+		"POST",
+		"/webhook",
+		strings.NewReader(`{"endpoint":"https://example.com","preset":"irc"}`),
+	)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"error":"invalid blueprint","fields":{"preset":"unknown preset: \"irc\""}}`, w.Body.String())
+}
+
+func Test_GET_Presets(t *testing.T) {
+	router := gin.Default()
+	newWebhooksRoute(webhookService{}).register(router)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/webhook/presets", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"name":"ntfy","method":"POST","content_type":"application/json","vars":[{"name":"topic","required":true,`)
+}
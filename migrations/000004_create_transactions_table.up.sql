@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id uuid NOT NULL,
+    sender text NOT NULL,
+    rcpt text NOT NULL,
+    raw blob NOT NULL,
+    created_at timestamp NOT NULL,
+
+    constraint transactions_pk primary key (id)
+);
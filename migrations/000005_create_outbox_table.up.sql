@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id uuid NOT NULL,
+    transaction_id uuid NOT NULL,
+    webhook_id uuid NOT NULL,
+    status text NOT NULL,
+    attempts integer NOT NULL,
+    next_attempt_at timestamp NOT NULL,
+    last_error text NOT NULL,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+
+    constraint outbox_pk primary key (id),
+    foreign key (transaction_id) references transactions(id),
+    foreign key (webhook_id) references webhooks(id)
+);
+
+CREATE INDEX IF NOT EXISTS outbox_due_idx ON outbox (status, next_attempt_at);
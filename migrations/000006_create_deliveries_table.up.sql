@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS deliveries (
+    id uuid NOT NULL,
+    transaction_id uuid NOT NULL,
+    webhook_id uuid NOT NULL,
+    address text NOT NULL,
+    body_hash text NOT NULL,
+    status_code integer NOT NULL,
+    response text NOT NULL,
+    latency integer NOT NULL,
+    error text NOT NULL,
+    created_at timestamp NOT NULL,
+
+    constraint deliveries_pk primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS deliveries_webhook_idx ON deliveries (webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS deliveries_transaction_idx ON deliveries (transaction_id, created_at);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS domains (
+    domain text NOT NULL,
+
+    constraint domains_pk primary key (domain)
+);
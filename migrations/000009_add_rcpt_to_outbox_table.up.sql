@@ -0,0 +1 @@
+ALTER TABLE outbox ADD COLUMN rcpt text NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS credentials (
+    username text NOT NULL,
+    password_hash text NOT NULL,
+    created_at timestamp NOT NULL,
+
+    constraint credentials_pk primary key (username)
+);
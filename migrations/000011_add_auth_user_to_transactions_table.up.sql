@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN auth_user text NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN auth_results text NOT NULL DEFAULT '';
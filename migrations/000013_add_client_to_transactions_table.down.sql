@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN tls;
+ALTER TABLE transactions DROP COLUMN helo;
+ALTER TABLE transactions DROP COLUMN remote_addr;
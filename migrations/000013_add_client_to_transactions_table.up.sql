@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN remote_addr text NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN helo text NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN tls boolean NOT NULL DEFAULT false;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS sender_rules (
+    id uuid NOT NULL,
+    address text NOT NULL,
+    action text NOT NULL,
+    pattern text NOT NULL,
+    created_at timestamp NOT NULL,
+
+    constraint sender_rules_pk primary key (id),
+    foreign key (address) references addresses(address)
+);
+
+CREATE INDEX IF NOT EXISTS sender_rules_address_idx ON sender_rules (address);
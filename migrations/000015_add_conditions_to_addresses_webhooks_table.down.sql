@@ -0,0 +1,5 @@
+ALTER TABLE addresses_webhooks DROP COLUMN body_contains;
+ALTER TABLE addresses_webhooks DROP COLUMN header_value;
+ALTER TABLE addresses_webhooks DROP COLUMN header_name;
+ALTER TABLE addresses_webhooks DROP COLUMN sender_pattern;
+ALTER TABLE addresses_webhooks DROP COLUMN subject_pattern;
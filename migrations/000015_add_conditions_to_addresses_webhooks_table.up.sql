@@ -0,0 +1,5 @@
+ALTER TABLE addresses_webhooks ADD COLUMN subject_pattern text NOT NULL DEFAULT '';
+ALTER TABLE addresses_webhooks ADD COLUMN sender_pattern text NOT NULL DEFAULT '';
+ALTER TABLE addresses_webhooks ADD COLUMN header_name text NOT NULL DEFAULT '';
+ALTER TABLE addresses_webhooks ADD COLUMN header_value text NOT NULL DEFAULT '';
+ALTER TABLE addresses_webhooks ADD COLUMN body_contains text NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE webhooks ADD COLUMN schema_mode text NOT NULL DEFAULT '';
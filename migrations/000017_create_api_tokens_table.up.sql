@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id uuid NOT NULL,
+    name text NOT NULL,
+    owner text NOT NULL,
+    token_hash text NOT NULL,
+    scopes text NOT NULL,
+    expires_at timestamp NULL,
+    created_at timestamp NOT NULL,
+
+    constraint api_tokens_pk primary key (id),
+    constraint api_tokens_hash_unique unique (token_hash)
+);
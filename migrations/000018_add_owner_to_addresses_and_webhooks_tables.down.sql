@@ -0,0 +1,2 @@
+ALTER TABLE webhooks DROP COLUMN owner;
+ALTER TABLE addresses DROP COLUMN owner;
@@ -0,0 +1,2 @@
+ALTER TABLE addresses ADD COLUMN owner text NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN owner text NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations of each database,
+// in the root for SQLite and in `postgres` for PostgreSQL.
+package migrations
+
+import "embed"
+
+//go:embed *.sql postgres/*.sql
+var FS embed.FS
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS credentials;
+DROP TABLE IF EXISTS deliveries;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS sender_rules;
+DROP TABLE IF EXISTS addresses_webhooks;
+DROP TABLE IF EXISTS addresses;
+DROP TABLE IF EXISTS domains;
+DROP TABLE IF EXISTS webhooks;
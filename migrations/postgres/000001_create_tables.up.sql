@@ -0,0 +1,130 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id uuid NOT NULL,
+    endpoint text NOT NULL,
+    auth text NOT NULL,
+    schema text NOT NULL,
+    schema_mode text NOT NULL DEFAULT '',
+    method text NOT NULL,
+    content_type text NOT NULL,
+    secret text NOT NULL DEFAULT '',
+    owner text NOT NULL DEFAULT '',
+
+    constraint webhooks_pk primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_owner_idx ON webhooks (owner);
+
+CREATE TABLE IF NOT EXISTS domains (
+    domain text NOT NULL,
+
+    constraint domains_pk primary key (domain)
+);
+
+CREATE TABLE IF NOT EXISTS addresses (
+    address text NOT NULL,
+    owner text NOT NULL DEFAULT '',
+
+    constraint addresses_pk primary key (address)
+);
+
+CREATE INDEX IF NOT EXISTS addresses_owner_idx ON addresses (owner);
+
+CREATE TABLE IF NOT EXISTS addresses_webhooks (
+    address text NOT NULL,
+    webhook_id uuid NOT NULL,
+    subject_pattern text NOT NULL DEFAULT '',
+    sender_pattern text NOT NULL DEFAULT '',
+    header_name text NOT NULL DEFAULT '',
+    header_value text NOT NULL DEFAULT '',
+    body_contains text NOT NULL DEFAULT '',
+
+    constraint addresses_webhooks_pk primary key (address, webhook_id),
+    foreign key (address) references addresses(address) on delete cascade,
+    foreign key (webhook_id) references webhooks(id) on delete cascade
+);
+
+CREATE TABLE IF NOT EXISTS sender_rules (
+    id uuid NOT NULL,
+    address text NOT NULL,
+    action text NOT NULL,
+    pattern text NOT NULL,
+    created_at timestamptz NOT NULL,
+
+    constraint sender_rules_pk primary key (id),
+    foreign key (address) references addresses(address) on delete cascade
+);
+
+CREATE INDEX IF NOT EXISTS sender_rules_address_idx ON sender_rules (address);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id uuid NOT NULL,
+    sender text NOT NULL,
+    rcpt text NOT NULL,
+    raw bytea NOT NULL,
+    auth_user text NOT NULL DEFAULT '',
+    auth_results text NOT NULL DEFAULT '',
+    remote_addr text NOT NULL DEFAULT '',
+    helo text NOT NULL DEFAULT '',
+    tls boolean NOT NULL DEFAULT false,
+    created_at timestamptz NOT NULL,
+
+    constraint transactions_pk primary key (id)
+);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id uuid NOT NULL,
+    transaction_id uuid NOT NULL,
+    webhook_id uuid NOT NULL,
+    rcpt text NOT NULL DEFAULT '',
+    status text NOT NULL,
+    attempts integer NOT NULL,
+    next_attempt_at timestamptz NOT NULL,
+    last_error text NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+
+    constraint outbox_pk primary key (id),
+    foreign key (transaction_id) references transactions(id),
+    foreign key (webhook_id) references webhooks(id) on delete cascade
+);
+
+CREATE INDEX IF NOT EXISTS outbox_due_idx ON outbox (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+    id uuid NOT NULL,
+    transaction_id uuid NOT NULL,
+    webhook_id uuid NOT NULL,
+    address text NOT NULL,
+    body_hash text NOT NULL,
+    status_code integer NOT NULL,
+    response text NOT NULL,
+    latency bigint NOT NULL,
+    error text NOT NULL,
+    created_at timestamptz NOT NULL,
+
+    constraint deliveries_pk primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS deliveries_webhook_idx ON deliveries (webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS deliveries_transaction_idx ON deliveries (transaction_id, created_at);
+
+CREATE TABLE IF NOT EXISTS credentials (
+    username text NOT NULL,
+    password_hash text NOT NULL,
+    created_at timestamptz NOT NULL,
+
+    constraint credentials_pk primary key (username)
+);
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id uuid NOT NULL,
+    name text NOT NULL,
+    owner text NOT NULL,
+    token_hash text NOT NULL,
+    scopes text NOT NULL,
+    expires_at timestamptz NULL,
+    created_at timestamptz NOT NULL,
+
+    constraint api_tokens_pk primary key (id),
+    constraint api_tokens_hash_unique unique (token_hash)
+);
@@ -0,0 +1,48 @@
+package queue
+
+import "time"
+
+// WithLogger sets a Logger into the Worker.
+func WithLogger(logger Logger) Option {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// WithConcurrency sets the number of Jobs delivered at the same time.
+func WithConcurrency(n int) Option {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+// WithMaxAttempts sets the number of attempts before a Job is given up.
+func WithMaxAttempts(n int) Option {
+	return func(w *Worker) {
+		w.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the delay after the first failure.
+// The delay doubles on each failure up to `max`.
+func WithBackoff(base time.Duration, max time.Duration) Option {
+	return func(w *Worker) {
+		w.backoff = base
+		w.maxBackoff = max
+	}
+}
+
+// WithInterval sets how often the Store is polled.
+func WithInterval(d time.Duration) Option {
+	return func(w *Worker) {
+		w.interval = d
+	}
+}
+
+// WithLease sets how long a claimed Job is held by the Worker.
+// It should be longer than a delivery takes.
+func WithLease(d time.Duration) Option {
+	return func(w *Worker) {
+		w.lease = d
+	}
+}
@@ -0,0 +1,173 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+// Job is a pending delivery of a Transaction to a Hook.
+type Job struct {
+	ID          uuid.UUID
+	Attempts    int // number of attempts already made.
+	Transaction session.Transaction
+	Hook        session.Hook
+}
+
+// Store persists Jobs.
+type Store interface {
+	// Claim returns up to `limit` Jobs that are due at `now`
+	// and holds them until `until` so that they are not claimed twice.
+	Claim(now time.Time, until time.Time, limit int) ([]Job, error)
+	// Done marks the Job as delivered.
+	Done(job Job) error
+	// Retry schedules the Job to be attempted again at `next`.
+	Retry(job Job, next time.Time, reason error) error
+	// Dead gives up the Job.
+	Dead(job Job, reason error) error
+}
+
+type Logger interface {
+	Info(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+type Option func(*Worker)
+
+// Worker delivers Jobs from a Store with a pool of goroutines.
+type Worker struct {
+	store  Store
+	logger Logger
+
+	concurrency int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	interval    time.Duration
+	lease       time.Duration
+}
+
+// New returns a Worker.
+func New(store Store, options ...Option) Worker {
+	w := Worker{
+		store:       store,
+		logger:      slog.Default(),
+		concurrency: 4,
+		maxAttempts: 8,
+		backoff:     time.Second * 10,
+		maxBackoff:  time.Hour,
+		interval:    time.Second,
+		lease:       time.Minute,
+	}
+	for _, opt := range options {
+		opt(&w)
+	}
+	return w
+}
+
+// Run polls the Store and delivers due Jobs until ctx is done.
+// Jobs being delivered are finished before Run returns.
+func (w Worker) Run(ctx context.Context) {
+	jobs := make(chan Job)
+	var wg sync.WaitGroup
+	wg.Add(w.concurrency)
+	for i := 0; i < w.concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				w.deliver(job)
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(jobs)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.dispatch(ctx, jobs)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w Worker) dispatch(ctx context.Context, jobs chan<- Job) {
+	now := time.Now().UTC()
+	claimed, err := w.store.Claim(now, now.Add(w.lease), w.concurrency)
+	if err != nil {
+		w.logger.Error("failed to claim jobs", "inner", err)
+		return
+	}
+	for _, job := range claimed {
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			// The rest is picked up again once the lease expires.
+			return
+		}
+	}
+}
+
+func (w Worker) deliver(job Job) {
+	reason := job.Hook.Send(job.Transaction)
+	job.Attempts++
+
+	var err error
+	switch {
+	case reason == nil:
+		err = w.store.Done(job)
+	case !retryable(reason) || job.Attempts >= w.maxAttempts:
+		w.logger.Error(
+			"gave up the delivery",
+			"id", job.ID.String(),
+			"transaction_id", job.Transaction.ID.String(),
+			"attempts", job.Attempts,
+			"reason", reason,
+		)
+		err = w.store.Dead(job, reason)
+	default:
+		next := time.Now().UTC().Add(w.delay(job.Attempts))
+		w.logger.Info(
+			"scheduled a retry of the delivery",
+			"id", job.ID.String(),
+			"transaction_id", job.Transaction.ID.String(),
+			"attempts", job.Attempts,
+			"next", next,
+			"reason", reason,
+		)
+		err = w.store.Retry(job, next, reason)
+	}
+	if err != nil {
+		w.logger.Error("failed to store the job", "inner", err, "id", job.ID.String())
+	}
+}
+
+// delay returns the exponential backoff after `attempts` failures.
+func (w Worker) delay(attempts int) time.Duration {
+	d := w.backoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= w.maxBackoff {
+			return w.maxBackoff
+		}
+	}
+	return d
+}
+
+// retryable reports whether err is worth another attempt.
+// Errors that don't tell, e.g. network errors, are retried.
+func retryable(err error) bool {
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+func testTransaction() session.Transaction {
+	m := strings.NewReader("From: alice<alice@mail.com>\nTo: bob<bob@mail.com>\nSubject: Subject\n\nhello")
+	t, err := session.NewTransaction(
+		uuid.New(),
+		session.MustParseAddr("alice@mail.com"),
+		session.MustParseAddr("bob@mail.com"),
+		m,
+	)
+	if err != nil {
+		panic(err)
+	}
+	return *t
+}
+
+type spyStore struct {
+	done  []Job
+	retry []Job
+	dead  []Job
+}
+
+func (s *spyStore) Claim(now time.Time, until time.Time, limit int) ([]Job, error) {
+	return nil, nil
+}
+
+func (s *spyStore) Done(job Job) error {
+	s.done = append(s.done, job)
+	return nil
+}
+
+func (s *spyStore) Retry(job Job, next time.Time, reason error) error {
+	s.retry = append(s.retry, job)
+	return nil
+}
+
+func (s *spyStore) Dead(job Job, reason error) error {
+	s.dead = append(s.dead, job)
+	return nil
+}
+
+type hookFunc func(session.Transaction) error
+
+func (f hookFunc) Send(t session.Transaction) error {
+	return f(t)
+}
+
+type permanentErr struct{}
+
+func (permanentErr) Error() string   { return "permanent" }
+func (permanentErr) Retryable() bool { return false }
+
+func newJob(attempts int, err error) Job {
+	return Job{
+		ID:          uuid.New(),
+		Attempts:    attempts,
+		Transaction: testTransaction(),
+		Hook:        hookFunc(func(_ session.Transaction) error { return err }),
+	}
+}
+
+func TestDeliver_Done(t *testing.T) {
+	store := spyStore{}
+	New(&store).deliver(newJob(0, nil))
+
+	assert.Len(t, store.done, 1)
+	assert.Equal(t, 1, store.done[0].Attempts)
+}
+
+func TestDeliver_Retry(t *testing.T) {
+	store := spyStore{}
+	New(&store, WithMaxAttempts(3)).deliver(newJob(1, errors.New("")))
+
+	assert.Len(t, store.retry, 1)
+	assert.Equal(t, 2, store.retry[0].Attempts)
+}
+
+func TestDeliver_Dead_MaxAttempts(t *testing.T) {
+	store := spyStore{}
+	New(&store, WithMaxAttempts(3)).deliver(newJob(2, errors.New("")))
+
+	assert.Len(t, store.dead, 1)
+	assert.Equal(t, 3, store.dead[0].Attempts)
+}
+
+func TestDeliver_Dead_NotRetryable(t *testing.T) {
+	store := spyStore{}
+	New(&store).deliver(newJob(0, permanentErr{}))
+
+	assert.Len(t, store.dead, 1)
+}
+
+func TestDelay(t *testing.T) {
+	w := New(&spyStore{}, WithBackoff(time.Second, time.Second*5))
+
+	assert.Equal(t, time.Second, w.delay(1))
+	assert.Equal(t, time.Second*2, w.delay(2))
+	assert.Equal(t, time.Second*4, w.delay(3))
+	assert.Equal(t, time.Second*5, w.delay(4))
+}
@@ -19,6 +19,8 @@ type Address struct {
 }
 
 // ParseAddr creates an Address from one line string.
+// The domain is lowercased since it is case-insensitive,
+// while the user is kept as it is.
 //
 // # Errors
 //   - If address is invalid format for mail address.
@@ -34,7 +36,7 @@ func ParseAddr(address string) (*Address, error) {
 	}
 	return &Address{
 		user:   strings.Split(addr.Address, "@")[0],
-		domain: strings.Split(addr.Address, "@")[1],
+		domain: strings.ToLower(strings.Split(addr.Address, "@")[1]),
 		name:   addr.Name,
 	}, nil
 }
@@ -20,3 +20,9 @@ func Test_NewRandomAddr(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func Test_ParseAddr_Domain(t *testing.T) {
+	addr := MustParseAddr("Alice <Alice@Mail.COM>")
+	assert.Equal(t, "Alice@mail.com", addr.String())
+	assert.Equal(t, "mail.com", addr.Domain())
+}
@@ -0,0 +1,33 @@
+package session
+
+import "github.com/jhillyerd/enmime"
+
+// Attachment is a file attached to or inlined in a mail.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string // the Content-ID to refer the part with the cid URL scheme, if any.
+	Content     []byte
+}
+
+func fromPart(p *enmime.Part) Attachment {
+	return Attachment{
+		Filename:    p.FileName,
+		ContentType: p.ContentType,
+		ContentID:   p.ContentID,
+		Content:     p.Content,
+	}
+}
+
+func fromParts(parts []*enmime.Part) []Attachment {
+	attachments := make([]Attachment, len(parts))
+	for i, p := range parts {
+		attachments[i] = fromPart(p)
+	}
+	return attachments
+}
+
+// Size returns the size of the decoded content in bytes.
+func (a Attachment) Size() int {
+	return len(a.Content)
+}
@@ -0,0 +1,66 @@
+package session
+
+import "strings"
+
+// AuthResult is the result of a sender authentication method.
+type AuthResult string
+
+const (
+	AuthNone      AuthResult = "none"
+	AuthPass      AuthResult = "pass"
+	AuthFail      AuthResult = "fail"
+	AuthSoftFail  AuthResult = "softfail"
+	AuthNeutral   AuthResult = "neutral"
+	AuthTempError AuthResult = "temperror"
+	AuthPermError AuthResult = "permerror"
+)
+
+// AuthResults are the results of SPF, DKIM and DMARC of a Transaction.
+// The zero value means the Transaction has not been verified.
+type AuthResults struct {
+	SPF         AuthResult `json:"spf,omitempty"`
+	SPFDomain   string     `json:"spf_domain,omitempty"` // the domain of MAIL FROM, or of HELO if MAIL FROM is empty.
+	DKIM        AuthResult `json:"dkim,omitempty"`
+	DKIMDomains []string   `json:"dkim_domains,omitempty"` // the domains of the valid signatures.
+	DMARC       AuthResult `json:"dmarc,omitempty"`
+	DMARCPolicy string     `json:"dmarc_policy,omitempty"` // the policy published by the domain of From.
+	FromDomain  string     `json:"from_domain,omitempty"`
+}
+
+// Verified reports whether the Transaction has been verified.
+func (r AuthResults) Verified() bool {
+	return r.SPF != "" || r.DKIM != "" || r.DMARC != ""
+}
+
+// String formats the results like the `Authentication-Results` header,
+// e.g. `spf=pass smtp.mailfrom=mail.com; dkim=pass header.d=mail.com; dmarc=pass header.from=mail.com`
+func (r AuthResults) String() string {
+	var results []string
+	if r.SPF != "" {
+		results = append(results, "spf="+string(r.SPF)+" smtp.mailfrom="+r.SPFDomain)
+	}
+	if r.DKIM != "" {
+		result := "dkim=" + string(r.DKIM)
+		if len(r.DKIMDomains) > 0 {
+			result += " header.d=" + strings.Join(r.DKIMDomains, ",")
+		}
+		results = append(results, result)
+	}
+	if r.DMARC != "" {
+		results = append(results, "dmarc="+string(r.DMARC)+" header.from="+r.FromDomain)
+	}
+	return strings.Join(results, "; ")
+}
+
+// Verifier verifies the sender of a Transaction.
+type Verifier interface {
+	// Verify returns the results of the sender authentication.
+	Verify(t Transaction) AuthResults
+}
+
+// nullVerifier leaves Transactions unverified.
+type nullVerifier struct{}
+
+func (v nullVerifier) Verify(_ Transaction) AuthResults {
+	return AuthResults{}
+}
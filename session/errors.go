@@ -11,4 +11,12 @@ var (
 
 	ErrValidation error = errors.New("validation failure")
 	ErrTimeout    error = errors.New("timeout")
+
+	ErrInvalidAddr   error = errors.New("invalid address")
+	ErrUnknownUser   error = errors.New("unknown user")
+	ErrUnknownDomain error = errors.New("unknown domain")
+	ErrSenderDenied  error = errors.New("sender denied")
+
+	ErrAuthUnsupported error = errors.New("authentication unsupported")
+	ErrAuthFailed      error = errors.New("authentication failed")
 )
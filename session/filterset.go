@@ -13,14 +13,28 @@ func AsFilter(f FilterSet) filterSet {
 	return filterSet{f}
 }
 
+// Validate validates the Transaction by the Filters of every recipient.
+// Each Filter validates the Transaction delivered for its recipient.
 func (f filterSet) Validate(trans Transaction) error {
-	addr, err := ParseAddr(trans.To())
-	if err != nil {
-		return err
+	var all []Filter
+	for _, rcpt := range trans.Rcpts() {
+		filters, err := f.FindFilters(rcpt)
+		if err != nil {
+			return err
+		}
+		for _, filter := range filters {
+			all = append(all, rcptFilter{filter, rcpt})
+		}
 	}
-	filters, err := f.FindFilters(*addr)
-	if err != nil {
-		return err
-	}
-	return Filters(filters).Validate(trans)
+	return Filters(all).Validate(trans)
+}
+
+// rcptFilter validates a Transaction delivered for the recipient.
+type rcptFilter struct {
+	Filter
+	rcpt Address
+}
+
+func (f rcptFilter) Validate(t Transaction) error {
+	return f.Filter.Validate(t.ForRcpt(f.rcpt))
 }
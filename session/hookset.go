@@ -13,14 +13,28 @@ func AsHook(h HookSet) hookSet {
 	return hookSet{h}
 }
 
+// Send sends the Transaction to the Hooks of every recipient.
+// Each Hook receives the Transaction delivered for its recipient.
 func (h hookSet) Send(trans Transaction) error {
-	addr, err := ParseAddr(trans.To())
-	if err != nil {
-		return err
+	var all []Hook
+	for _, rcpt := range trans.Rcpts() {
+		hooks, err := h.FindHooks(rcpt)
+		if err != nil {
+			return err
+		}
+		for _, hook := range hooks {
+			all = append(all, rcptHook{hook, rcpt})
+		}
 	}
-	hooks, err := h.FindHooks(*addr)
-	if err != nil {
-		return err
-	}
-	return HooksSome(hooks).Send(trans)
+	return HooksSome(all).Send(trans)
+}
+
+// rcptHook sends a Transaction delivered for the recipient.
+type rcptHook struct {
+	Hook
+	rcpt Address
+}
+
+func (h rcptHook) Send(t Transaction) error {
+	return h.Hook.Send(t.ForRcpt(h.rcpt))
 }
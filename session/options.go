@@ -28,6 +28,46 @@ func WithHooksSome(xs ...Hook) Option {
 	}
 }
 
+// WithRcptPolicies sets one or more policies into Session.
+// Each policies execute in order when a recipient is set.
+func WithRcptPolicies(xs ...RcptPolicy) Option {
+	return func(s *Session) {
+		s.policy = RcptPolicies(xs)
+	}
+}
+
+// WithSenderPolicies sets one or more policies into Session.
+// Each policies execute in order when a recipient is set,
+// checking the sender given by MAIL FROM.
+func WithSenderPolicies(xs ...SenderPolicy) Option {
+	return func(s *Session) {
+		s.senders = SenderPolicies(xs)
+	}
+}
+
+// WithAuthenticator sets an Authenticator into the Session.
+// Transactions of authenticated Sessions skip the Filters.
+func WithAuthenticator(a Authenticator) Option {
+	return func(s *Session) {
+		s.auth = a
+	}
+}
+
+// WithVerifier sets a Verifier into the Session.
+// Transactions are verified before the Filters validate them.
+func WithVerifier(v Verifier) Option {
+	return func(s *Session) {
+		s.verifier = v
+	}
+}
+
+// WithClient sets the SMTP client which opened the Session.
+func WithClient(c Client) Option {
+	return func(s *Session) {
+		s.client = c
+	}
+}
+
 // WithLogger sets a Logger into the Session.
 func WithLogger(logger Logger) Option {
 	return func(s *Session) {
@@ -2,8 +2,13 @@ package session
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"net"
+	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -37,6 +42,58 @@ func (f Filters) Validate(t Transaction) error {
 	return sync.TryAll(t, fs...)
 }
 
+// RcptPolicy determines the recipient should be accepted.
+type RcptPolicy interface {
+	// AcceptRcpt returns an error if the recipient is refused.
+	AcceptRcpt(rcpt Address) error
+}
+
+// nullPolicy always returns nil on AcceptRcpt.
+type nullPolicy struct{}
+
+func (p nullPolicy) AcceptRcpt(_ Address) error {
+	return nil
+}
+
+// RcptPolicies is an array of RcptPolicy.
+// The recipient is refused by the first policy that refuses it.
+type RcptPolicies []RcptPolicy
+
+func (p RcptPolicies) AcceptRcpt(rcpt Address) error {
+	for _, x := range p {
+		if err := x.AcceptRcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SenderPolicy determines the sender should be accepted by the recipient.
+type SenderPolicy interface {
+	// AcceptSender returns an error if the recipient refuses the sender.
+	AcceptSender(sender Address, rcpt Address) error
+}
+
+// nullSenderPolicy always returns nil on AcceptSender.
+type nullSenderPolicy struct{}
+
+func (p nullSenderPolicy) AcceptSender(_ Address, _ Address) error {
+	return nil
+}
+
+// SenderPolicies is an array of SenderPolicy.
+// The sender is refused by the first policy that refuses it.
+type SenderPolicies []SenderPolicy
+
+func (p SenderPolicies) AcceptSender(sender Address, rcpt Address) error {
+	for _, x := range p {
+		if err := x.AcceptSender(sender, rcpt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Hook hooks
 type Hook interface {
 	// Send sends a Transaction.
@@ -72,6 +129,26 @@ func (h HooksSome) Send(t Transaction) error {
 	return sync.TrySome(t, prepareHooks(h)...)
 }
 
+// Client is the SMTP client which opened the Session.
+type Client struct {
+	IP   net.IP
+	Helo string // the name given by HELO or EHLO.
+	TLS  bool   // whether the connection is secured by TLS.
+}
+
+// Authenticator verifies credentials of a client.
+type Authenticator interface {
+	// Authenticate returns an error if the password does not match the username.
+	Authenticate(username string, password string) error
+}
+
+// nullAuthenticator refuses any credentials.
+type nullAuthenticator struct{}
+
+func (a nullAuthenticator) Authenticate(_ string, _ string) error {
+	return ErrAuthUnsupported
+}
+
 type Logger interface {
 	Error(meg string, args ...any)
 }
@@ -80,11 +157,17 @@ type Session struct {
 	Filter
 	Hook
 
-	logger Logger
+	logger   Logger
+	policy   RcptPolicy
+	senders  SenderPolicy
+	auth     Authenticator
+	verifier Verifier
+	client   Client
 
 	id     uuid.UUID
+	user   string
 	sender *Address
-	rcpt   *Address
+	rcpts  []Address
 	data   io.Reader
 
 	timeout time.Duration
@@ -94,11 +177,15 @@ type Option func(*Session)
 
 func New(options ...Option) Session {
 	s := Session{
-		Filter:  nullFilter{},
-		Hook:    nullHook{},
-		id:      uuid.New(),
-		logger:  slog.Default(),
-		timeout: time.Second * 10,
+		Filter:   nullFilter{},
+		Hook:     nullHook{},
+		policy:   nullPolicy{},
+		senders:  nullSenderPolicy{},
+		auth:     nullAuthenticator{},
+		verifier: nullVerifier{},
+		id:       uuid.New(),
+		logger:   slog.Default(),
+		timeout:  time.Second * 10,
 	}
 	for _, opt := range options {
 		opt(&s)
@@ -111,23 +198,53 @@ func (s Session) ID() uuid.UUID {
 	return s.id
 }
 
+// Login authenticates the client for the rest of the Session.
+//
+// # Errors
+//   - ErrAuthUnsupported if no Authenticator is set.
+//   - ErrAuthFailed if the credentials are wrong.
+func (s *Session) Login(username string, password string) error {
+	if err := s.auth.Authenticate(username, password); err != nil {
+		return err
+	}
+	s.user = username
+	return nil
+}
+
 // SetMail parse a sender address and sets it into the Session.
 func (s *Session) SetMail(addr string) error {
 	a, err := ParseAddr(addr)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrInvalidAddr, err)
 	}
 	s.sender = a
 	return nil
 }
 
-// SetRcpt parse a recipient address and sets it into the Session.
+// SetRcpt parse a recipient address and adds it into the Session.
+// Returns an error if the RcptPolicy refuses the address,
+// or if the SenderPolicy of the address refuses the sender.
+// The sender of an authenticated Session is not checked.
+// Adding the same address twice has no effect.
 func (s *Session) SetRcpt(addr string) error {
 	a, err := ParseAddr(addr)
 	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAddr, err)
+	}
+	if err := s.policy.AcceptRcpt(*a); err != nil {
 		return err
 	}
-	s.rcpt = a
+	if s.sender != nil && s.user == "" {
+		if err := s.senders.AcceptSender(*s.sender, *a); err != nil {
+			return err
+		}
+	}
+	for _, rcpt := range s.rcpts {
+		if rcpt.String() == a.String() {
+			return nil
+		}
+	}
+	s.rcpts = append(s.rcpts, *a)
 	return nil
 }
 
@@ -138,17 +255,22 @@ func (s *Session) SetData(r io.Reader) error {
 }
 
 // Reset sets default values into Session.
+// Each mail transaction on the same connection gets its own ID.
+// The authenticated user is kept.
 func (s *Session) Reset() {
+	s.id = uuid.New()
 	s.sender = nil
-	s.rcpt = nil
+	s.rcpts = nil
 	s.data = nil
 }
 
 // Commit creates, validates, and sends a Transaction.
+// Transactions submitted by an authenticated user are not validated.
 //
 // # Errors
-//   - `sender`, `rcpt`, or `envelope` is nil.
-//   - Validate failed.
+//   - `sender`, `rcpts`, or `envelope` is nil.
+//   - ErrValidation if a Filter refused the Transaction.
+//   - Validate failed for another reason, e.g. a failing database.
 //   - Send failed.
 func (s Session) Commit() error {
 	trans, err := s.IntoTransaction()
@@ -159,18 +281,27 @@ func (s Session) Commit() error {
 	ec := make(chan error, 1)
 	go func() {
 		defer close(ec)
+		if trans.Authenticated() {
+			ec <- s.Send(*trans)
+			return
+		}
+		*trans = trans.WithAuthResults(s.verifier.Verify(*trans))
 		if err := s.Validate(*trans); err != nil {
 			s.logger.Error(
 				"validation failure",
 				"reason", err,
 				"id", trans.ID.String(),
 				"sender", trans.SenderAddress(),
-				"rcpt", trans.RcptAddress(),
+				"rcpts", trans.Rcpts(),
+				"remote_addr", trans.RemoteAddr(),
 				"from", trans.From(),
 				"to", trans.To(),
 				"subject", trans.Subject(),
 				"text", trans.Text(),
 			)
+			if refused(err) && !errors.Is(err, ErrValidation) {
+				err = fmt.Errorf("%w: %w", ErrValidation, err)
+			}
 			ec <- err
 			return
 		}
@@ -185,28 +316,63 @@ func (s Session) Commit() error {
 	}
 }
 
+// refused reports whether the error refuses the Transaction for good.
+// Any other error, e.g. a failing database, is temporary.
+func refused(err error) bool {
+	return errors.Is(err, ErrValidation) ||
+		errors.Is(err, ErrUnknownUser) ||
+		errors.Is(err, ErrUnknownDomain) ||
+		errors.Is(err, ErrSenderDenied)
+}
+
 func (s Session) IntoTransaction() (*Transaction, error) {
 	if s.data == nil {
 		return nil, ErrNilEnvelope
 	}
-	if s.rcpt == nil {
+	if len(s.rcpts) == 0 {
 		return nil, ErrNilRcpt
 	}
 	if s.sender == nil {
 		return nil, ErrNilSender
 	}
-	return NewTransaction(s.id, *s.sender, *s.rcpt, s.data)
+	trans, err := NewTransactionWithRcpts(s.id, *s.sender, s.rcpts, s.data)
+	if err != nil {
+		return nil, err
+	}
+	*trans = trans.FromClient(s.client)
+	if s.user != "" {
+		*trans = trans.AuthenticatedAs(s.user)
+	}
+	return trans, nil
 }
 
 type Transaction struct {
 	ID       uuid.UUID
 	sender   Address
-	rcpt     Address
+	rcpt     Address   // the recipient the Transaction is delivered for.
+	rcpts    []Address // all recipients of the envelope.
+	user     string    // the user who submitted the Transaction, if authenticated.
+	client   Client
+	results  AuthResults
 	envelope enmime.Envelope
 	raw      []byte
 }
 
+// NewTransaction creates a Transaction for one recipient.
 func NewTransaction(id uuid.UUID, sender Address, rcpt Address, body io.Reader) (*Transaction, error) {
+	return NewTransactionWithRcpts(id, sender, []Address{rcpt}, body)
+}
+
+// NewTransactionWithRcpts creates a Transaction for one or more recipients.
+// It is delivered for the first recipient until ForRcpt is called.
+//
+// # Errors
+//   - `rcpts` is empty.
+//   - The body is not a mail.
+func NewTransactionWithRcpts(id uuid.UUID, sender Address, rcpts []Address, body io.Reader) (*Transaction, error) {
+	if len(rcpts) == 0 {
+		return nil, ErrNilRcpt
+	}
 	var buf bytes.Buffer
 	tee := io.TeeReader(body, &buf)
 	env, err := enmime.ReadEnvelope(tee)
@@ -216,7 +382,8 @@ func NewTransaction(id uuid.UUID, sender Address, rcpt Address, body io.Reader)
 	return &Transaction{
 		ID:       id,
 		sender:   sender,
-		rcpt:     rcpt,
+		rcpt:     rcpts[0],
+		rcpts:    rcpts,
 		envelope: *env,
 		raw:      buf.Bytes(),
 	}, nil
@@ -238,6 +405,76 @@ func (t Transaction) RcptAddress() string {
 	return t.rcpt.String()
 }
 
+// Rcpts returns all recipients of the envelope.
+func (t Transaction) Rcpts() []Address {
+	return t.rcpts
+}
+
+// ForRcpt returns a copy of the Transaction delivered for the recipient.
+func (t Transaction) ForRcpt(rcpt Address) Transaction {
+	t.rcpt = rcpt
+	return t
+}
+
+// AuthenticatedAs returns the Transaction submitted by the authenticated user.
+func (t Transaction) AuthenticatedAs(user string) Transaction {
+	t.user = user
+	return t
+}
+
+// Authenticated reports whether the Transaction was submitted by an authenticated user.
+func (t Transaction) Authenticated() bool {
+	return t.user != ""
+}
+
+// AuthUser returns the user who submitted the Transaction,
+// or an empty string if the client was not authenticated.
+func (t Transaction) AuthUser() string {
+	return t.user
+}
+
+// FromClient returns a copy of the Transaction received from the client.
+func (t Transaction) FromClient(c Client) Transaction {
+	t.client = c
+	return t
+}
+
+// ClientIP returns the IP of the SMTP client, or nil if unknown.
+func (t Transaction) ClientIP() net.IP {
+	return t.client.IP
+}
+
+// RemoteAddr returns the IP of the SMTP client as a string,
+// or an empty string if unknown.
+func (t Transaction) RemoteAddr() string {
+	if t.client.IP == nil {
+		return ""
+	}
+	return t.client.IP.String()
+}
+
+// Helo returns the name the SMTP client greeted with.
+func (t Transaction) Helo() string {
+	return t.client.Helo
+}
+
+// TLS reports whether the Transaction was received over TLS.
+func (t Transaction) TLS() bool {
+	return t.client.TLS
+}
+
+// WithAuthResults returns a copy of the Transaction with the results of the sender authentication.
+func (t Transaction) WithAuthResults(r AuthResults) Transaction {
+	t.results = r
+	return t
+}
+
+// AuthResults returns the results of SPF, DKIM and DMARC.
+// The results are empty unless a Verifier is set.
+func (t Transaction) AuthResults() AuthResults {
+	return t.results
+}
+
 func (t Transaction) HTML() string {
 	return t.envelope.HTML
 }
@@ -250,6 +487,16 @@ func (t Transaction) From() string {
 	return t.envelope.GetHeader("From")
 }
 
+// Attachments returns the parts attached to the mail.
+func (t Transaction) Attachments() []Attachment {
+	return fromParts(t.envelope.Attachments)
+}
+
+// Inlines returns the parts inlined in the mail, e.g. images the HTML refers to.
+func (t Transaction) Inlines() []Attachment {
+	return fromParts(t.envelope.Inlines)
+}
+
 func (t Transaction) Raw() []byte {
 	return t.raw
 }
@@ -261,3 +508,66 @@ func (t Transaction) To() string {
 func (t Transaction) Subject() string {
 	return t.envelope.GetHeader("Subject")
 }
+
+// Header returns the first value of the header named `name`, decoded from RFC 2047,
+// or an empty string if the mail has no such header.
+func (t Transaction) Header(name string) string {
+	return t.envelope.GetHeader(name)
+}
+
+// Headers returns all values of the header named `name`, decoded from RFC 2047.
+func (t Transaction) Headers(name string) []string {
+	return t.envelope.GetHeaderValues(name)
+}
+
+func (t Transaction) Cc() string {
+	return t.envelope.GetHeader("Cc")
+}
+
+func (t Transaction) ReplyTo() string {
+	return t.envelope.GetHeader("Reply-To")
+}
+
+// MessageID returns the Message-ID header, e.g. `<id@mail.com>`.
+func (t Transaction) MessageID() string {
+	return strings.TrimSpace(t.envelope.GetHeader("Message-ID"))
+}
+
+// InReplyTo returns the In-Reply-To header, the Message-ID the mail replies to.
+func (t Transaction) InReplyTo() string {
+	return strings.TrimSpace(t.envelope.GetHeader("In-Reply-To"))
+}
+
+// References returns the Message-IDs in the References header, from the oldest.
+func (t Transaction) References() []string {
+	return strings.Fields(t.envelope.GetHeader("References"))
+}
+
+// Date returns the Date header, or the zero time if it is missing or malformed.
+func (t Transaction) Date() time.Time {
+	date, err := t.envelope.Date()
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// ToAddresses returns the addresses in the To header,
+// or nil if it is missing or malformed.
+func (t Transaction) ToAddresses() []*mail.Address {
+	return t.addressList("To")
+}
+
+// CcAddresses returns the addresses in the Cc header,
+// or nil if it is missing or malformed.
+func (t Transaction) CcAddresses() []*mail.Address {
+	return t.addressList("Cc")
+}
+
+func (t Transaction) addressList(key string) []*mail.Address {
+	list, err := t.envelope.AddressList(key)
+	if err != nil {
+		return nil
+	}
+	return list
+}
@@ -2,11 +2,13 @@ package session
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -70,6 +72,31 @@ func TestValidation(t *testing.T) {
 	}
 }
 
+// brokenFilterSet fails to find the filters, as a database going down would.
+type brokenFilterSet struct{}
+
+func (f brokenFilterSet) FindFilters(_ Address) ([]Filter, error) {
+	return nil, errors.New("sql error")
+}
+
+func TestValidation_Temporary(t *testing.T) {
+	session := New(
+		WithFilters(AsFilter(brokenFilterSet{})),
+	)
+	if err := session.SetMail("alice<alice@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	if err := session.SetRcpt("bob<bob@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	if err := session.SetData(createMail("hello")); err != nil {
+		t.Error(err)
+	}
+	err := session.Commit()
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrValidation)
+}
+
 type tooLongHook struct{}
 
 func (t tooLongHook) Send(_ Transaction) error {
@@ -95,3 +122,226 @@ func TestTimeout(t *testing.T) {
 		t.Error("should fails")
 	}
 }
+
+type domainPolicy string
+
+func (p domainPolicy) AcceptRcpt(rcpt Address) error {
+	if rcpt.Domain() != string(p) {
+		return errors.New("unknown domain")
+	}
+	return nil
+}
+
+func TestRcptPolicy(t *testing.T) {
+	session := New(
+		WithRcptPolicies(domainPolicy("mail.com")),
+	)
+	if err := session.SetRcpt("bob<bob@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	if err := session.SetRcpt("bob<bob@other.com>"); err == nil {
+		t.Error("should fails")
+	}
+	assert.Equal(t, "bob@mail.com", session.rcpts[0].String())
+	assert.Len(t, session.rcpts, 1)
+}
+
+// denySender refuses every sender for the recipients.
+type denySender map[string]bool
+
+func (p denySender) AcceptSender(sender Address, rcpt Address) error {
+	if p[rcpt.String()] {
+		return fmt.Errorf("%w: %s", ErrSenderDenied, sender)
+	}
+	return nil
+}
+
+func TestSenderPolicy(t *testing.T) {
+	spy := spyHook{}
+	session := New(
+		WithSenderPolicies(denySender{"carol@mail.com": true}),
+		WithHooksAll(&spy),
+	)
+	if err := session.SetMail("alice<alice@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	if err := session.SetRcpt("bob<bob@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	assert.ErrorIs(t, session.SetRcpt("carol<carol@mail.com>"), ErrSenderDenied)
+	if err := session.SetData(createMail("hello")); err != nil {
+		t.Error(err)
+	}
+	assert.NoError(t, session.Commit())
+	assert.Len(t, spy.res.Rcpts(), 1)
+	assert.Equal(t, "bob@mail.com", spy.res.RcptAddress())
+}
+
+func TestSenderPolicy_Authenticated(t *testing.T) {
+	session := New(
+		WithSenderPolicies(denySender{"carol@mail.com": true}),
+		WithAuthenticator(passwords{"alice": "pass"}),
+	)
+	assert.NoError(t, session.Login("alice", "pass"))
+	if err := session.SetMail("alice<alice@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	assert.NoError(t, session.SetRcpt("carol<carol@mail.com>"))
+}
+
+type mapHookSet map[string]Hook
+
+func (m mapHookSet) FindHooks(addr Address) ([]Hook, error) {
+	if h, ok := m[addr.String()]; ok {
+		return []Hook{h}, nil
+	}
+	return []Hook{}, nil
+}
+
+func TestMultipleRcpts(t *testing.T) {
+	bob := spyHook{}
+	carol := spyHook{}
+	session := New(
+		WithHooksAll(AsHook(mapHookSet{
+			"bob@mail.com":   &bob,
+			"carol@mail.com": &carol,
+		})),
+	)
+	if err := session.SetMail("alice<alice@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	for _, rcpt := range []string{"bob@mail.com", "carol@mail.com", "bob@mail.com", "dave@mail.com"} {
+		if err := session.SetRcpt(rcpt); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := session.SetData(createMail("hello")); err != nil {
+		t.Error(err)
+	}
+	if err := session.Commit(); err != nil {
+		t.Error(err)
+	}
+	assert.Equal(t, "bob@mail.com", bob.res.RcptAddress())
+	assert.Equal(t, "carol@mail.com", carol.res.RcptAddress())
+	assert.Len(t, carol.res.Rcpts(), 3)
+}
+
+type passwords map[string]string
+
+func (p passwords) Authenticate(username string, password string) error {
+	if p[username] != password {
+		return ErrAuthFailed
+	}
+	return nil
+}
+
+func TestLogin(t *testing.T) {
+	spy := spyHook{}
+	session := New(
+		WithAuthenticator(passwords{"service": "pass"}),
+		WithFilters(errFilter{}),
+		WithHooksAll(&spy),
+	)
+	assert.ErrorIs(t, session.Login("service", "wrong"), ErrAuthFailed)
+	if err := session.Login("service", "pass"); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.SetMail("alice<alice@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	if err := session.SetRcpt("bob<bob@mail.com>"); err != nil {
+		t.Error(err)
+	}
+	if err := session.SetData(createMail("<strong>hello</strong>")); err != nil {
+		t.Error(err)
+	}
+	if err := session.Commit(); err != nil {
+		t.Error(err)
+	}
+	assert.True(t, spy.res.Authenticated())
+	assert.Equal(t, "service", spy.res.AuthUser())
+}
+
+func TestLogin_Unsupported(t *testing.T) {
+	session := New()
+	assert.ErrorIs(t, session.Login("service", "pass"), ErrAuthUnsupported)
+}
+
+func TestAttachments(t *testing.T) {
+	mail := "From: alice<alice@mail.com>\r\n" +
+		"To: bob<bob@mail.com>\r\n" +
+		"Subject: Subject\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"b\"\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"hello\r\n" +
+		"--b\r\n" +
+		"Content-Type: image/png\r\n" +
+		"Content-Disposition: inline; filename=\"logo.png\"\r\n" +
+		"Content-ID: <logo>\r\n" +
+		"\r\n" +
+		"png\r\n" +
+		"--b\r\n" +
+		"Content-Type: text/csv\r\n" +
+		"Content-Disposition: attachment; filename=\"report.csv\"\r\n" +
+		"\r\n" +
+		"id,name\r\n" +
+		"--b--\r\n"
+	trans, err := NewTransaction(uuid.New(), MustParseAddr("alice@mail.com"), MustParseAddr("bob@mail.com"), strings.NewReader(mail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, trans.Attachments(), 1) {
+		a := trans.Attachments()[0]
+		assert.Equal(t, "report.csv", a.Filename)
+		assert.Equal(t, "text/csv", a.ContentType)
+		assert.Equal(t, "id,name", string(a.Content))
+		assert.Equal(t, 7, a.Size())
+	}
+	if assert.Len(t, trans.Inlines(), 1) {
+		assert.Equal(t, "logo", trans.Inlines()[0].ContentID)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	mail := "From: alice<alice@mail.com>\r\n" +
+		"To: bob<bob@mail.com>, carol@mail.com\r\n" +
+		"Cc: =?UTF-8?B?44OH44Kk44OT44OD44OJ?= <dave@mail.com>\r\n" +
+		"Reply-To: noreply@github.com\r\n" +
+		"Subject: Subject\r\n" +
+		"Date: Mon, 02 Jan 2006 15:04:05 +0900\r\n" +
+		"Message-ID: <3@mail.com>\r\n" +
+		"In-Reply-To: <2@mail.com>\r\n" +
+		"References: <1@mail.com>\r\n <2@mail.com>\r\n" +
+		"X-GitHub-Reason: review_requested\r\n" +
+		"Received: from a\r\n" +
+		"Received: from b\r\n" +
+		"\r\n" +
+		"hello\r\n"
+	trans, err := NewTransaction(uuid.New(), MustParseAddr("alice@mail.com"), MustParseAddr("bob@mail.com"), strings.NewReader(mail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "review_requested", trans.Header("x-github-reason"))
+	assert.Equal(t, "", trans.Header("List-Id"))
+	assert.Equal(t, []string{"from a", "from b"}, trans.Headers("Received"))
+	assert.Equal(t, "デイビッド <dave@mail.com>", trans.Cc())
+	assert.Equal(t, "noreply@github.com", trans.ReplyTo())
+	assert.Equal(t, "<3@mail.com>", trans.MessageID())
+	assert.Equal(t, "<2@mail.com>", trans.InReplyTo())
+	assert.Equal(t, []string{"<1@mail.com>", "<2@mail.com>"}, trans.References())
+	assert.True(t, time.Date(2006, 1, 2, 6, 4, 5, 0, time.UTC).Equal(trans.Date()))
+
+	to := trans.ToAddresses()
+	if assert.Len(t, to, 2) {
+		assert.Equal(t, "bob", to[0].Name)
+		assert.Equal(t, "carol@mail.com", to[1].Address)
+	}
+	cc := trans.CcAddresses()
+	if assert.Len(t, cc, 1) {
+		assert.Equal(t, "デイビッド", cc[0].Name)
+	}
+}
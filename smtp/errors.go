@@ -0,0 +1,100 @@
+package smtp
+
+import (
+	"errors"
+
+	"github.com/emersion/go-smtp"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+var (
+	errBadSender = &smtp.SMTPError{
+		Code:         501,
+		EnhancedCode: smtp.EnhancedCode{5, 1, 7},
+		Message:      "Bad sender address syntax",
+	}
+	errBadRcpt = &smtp.SMTPError{
+		Code:         501,
+		EnhancedCode: smtp.EnhancedCode{5, 1, 3},
+		Message:      "Bad recipient address syntax",
+	}
+	errUnknownUser = &smtp.SMTPError{
+		Code:         550,
+		EnhancedCode: smtp.EnhancedCode{5, 1, 1},
+		Message:      "No such user here",
+	}
+	errRelayDenied = &smtp.SMTPError{
+		Code:         550,
+		EnhancedCode: smtp.EnhancedCode{5, 7, 1},
+		Message:      "Relay access denied",
+	}
+	errSenderDenied = &smtp.SMTPError{
+		Code:         550,
+		EnhancedCode: smtp.EnhancedCode{5, 7, 1},
+		Message:      "Sender denied by the recipient",
+	}
+	errRejected = &smtp.SMTPError{
+		Code:         550,
+		EnhancedCode: smtp.EnhancedCode{5, 7, 1},
+		Message:      "Message rejected",
+	}
+	errTLSRequired = &smtp.SMTPError{
+		Code:         530,
+		EnhancedCode: smtp.EnhancedCode{5, 7, 0},
+		Message:      "Must issue a STARTTLS command first",
+	}
+	errAuthTemporary = &smtp.SMTPError{
+		Code:         454,
+		EnhancedCode: smtp.EnhancedCode{4, 7, 0},
+		Message:      "Temporary authentication failure",
+	}
+	errTemporary = &smtp.SMTPError{
+		Code:         451,
+		EnhancedCode: smtp.EnhancedCode{4, 3, 0},
+		Message:      "Temporary failure, try again later",
+	}
+)
+
+// authError converts an error on AUTH into a reply without internal messages.
+func authError(err error) error {
+	switch {
+	case errors.Is(err, session.ErrAuthUnsupported):
+		return smtp.ErrAuthUnsupported
+	case errors.Is(err, session.ErrAuthFailed):
+		return smtp.ErrAuthFailed
+	default:
+		return errAuthTemporary
+	}
+}
+
+// mailError converts an error on MAIL into a reply without internal messages.
+func mailError(err error) error {
+	if errors.Is(err, session.ErrInvalidAddr) {
+		return errBadSender
+	}
+	return errTemporary
+}
+
+// rcptError converts an error on RCPT into a reply without internal messages.
+func rcptError(err error) error {
+	switch {
+	case errors.Is(err, session.ErrInvalidAddr):
+		return errBadRcpt
+	case errors.Is(err, session.ErrUnknownUser):
+		return errUnknownUser
+	case errors.Is(err, session.ErrUnknownDomain):
+		return errRelayDenied
+	case errors.Is(err, session.ErrSenderDenied):
+		return errSenderDenied
+	default:
+		return errTemporary
+	}
+}
+
+// dataError converts an error on DATA into a reply without internal messages.
+func dataError(err error) error {
+	if errors.Is(err, session.ErrValidation) {
+		return errRejected
+	}
+	return errTemporary
+}
@@ -0,0 +1,35 @@
+package smtp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/emersion/go-smtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+func code(err error) int {
+	return err.(*smtp.SMTPError).Code
+}
+
+func TestRcptError(t *testing.T) {
+	assert.Equal(t, 550, code(rcptError(fmt.Errorf("x: %w", session.ErrUnknownUser))))
+	assert.Equal(t, smtp.EnhancedCode{5, 1, 1}, rcptError(session.ErrUnknownUser).(*smtp.SMTPError).EnhancedCode)
+	assert.Equal(t, 550, code(rcptError(session.ErrUnknownDomain)))
+	assert.Equal(t, 501, code(rcptError(session.ErrInvalidAddr)))
+	assert.Equal(t, smtp.EnhancedCode{5, 7, 1}, rcptError(fmt.Errorf("%w: spam", session.ErrSenderDenied)).(*smtp.SMTPError).EnhancedCode)
+	assert.Equal(t, 451, code(rcptError(errors.New("sql error"))))
+}
+
+func TestDataError(t *testing.T) {
+	assert.Equal(t, 550, code(dataError(fmt.Errorf("%w: spam", session.ErrValidation))))
+	assert.Equal(t, 451, code(dataError(session.ErrTimeout)))
+}
+
+func TestAuthError(t *testing.T) {
+	assert.Equal(t, 535, code(authError(fmt.Errorf("x: %w", session.ErrAuthFailed))))
+	assert.Equal(t, 502, code(authError(session.ErrAuthUnsupported)))
+	assert.Equal(t, 454, code(authError(errors.New("sql error"))))
+}
@@ -0,0 +1,83 @@
+package smtp
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+// Tracker keeps the connections accepted by its listeners,
+// so that the sessions still open after smtp.Server.Shutdown can be cut off.
+type Tracker struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewTracker returns a Tracker with no connections.
+func NewTracker() *Tracker {
+	return &Tracker{conns: map[net.Conn]struct{}{}}
+}
+
+// Listener returns the listener tracking the connections it accepts.
+// It has to wrap the TCP listener beneath tls.NewListener,
+// since the server tells TLS connections by their type.
+func (t *Tracker) Listener(l net.Listener) net.Listener {
+	return trackedListener{Listener: l, tracker: t}
+}
+
+// Close closes every connection still open.
+func (t *Tracker) Close() error {
+	t.mu.Lock()
+	conns := make([]net.Conn, 0, len(t.conns))
+	for c := range t.conns {
+		conns = append(conns, c)
+	}
+	t.mu.Unlock()
+
+	var errs []error
+	for _, c := range conns {
+		if err := c.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Len returns the number of the connections still open.
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.conns)
+}
+
+type trackedListener struct {
+	net.Listener
+	tracker *Tracker
+}
+
+func (l trackedListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	conn := &trackedConn{Conn: c, tracker: l.tracker}
+	l.tracker.mu.Lock()
+	l.tracker.conns[conn] = struct{}{}
+	l.tracker.mu.Unlock()
+	return conn, nil
+}
+
+type trackedConn struct {
+	net.Conn
+	tracker *Tracker
+	once    sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.tracker.mu.Lock()
+		delete(c.tracker.conns, c)
+		c.tracker.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
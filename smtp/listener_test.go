@@ -0,0 +1,40 @@
+package smtp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-smtp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	server := New()
+	server.Domain = "mx.mail.com"
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(tracker.Listener(l))
+
+	// An idle client keeps its session open over Shutdown.
+	c, err := smtp.Dial(l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	assert.NoError(t, c.Hello("client.com"))
+	assert.Equal(t, 1, tracker.Len())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	assert.ErrorIs(t, server.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Equal(t, 1, tracker.Len())
+
+	assert.NoError(t, tracker.Close())
+	assert.Error(t, c.Noop())
+	assert.Equal(t, 0, tracker.Len())
+}
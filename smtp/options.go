@@ -1,6 +1,8 @@
 package smtp
 
 import (
+	"crypto/tls"
+
 	"github.com/zen-en-tonal/mtw/session"
 )
 
@@ -17,3 +19,18 @@ func WithLogger(logger Logger) Option {
 		b.logger = logger
 	}
 }
+
+// WithTLS enables STARTTLS with the config.
+// The same config is used by ListenAndServeTLS for implicit TLS.
+func WithTLS(config *tls.Config) Option {
+	return func(b *backend) {
+		b.tlsConfig = config
+	}
+}
+
+// WithRequireTLS refuses MAIL until the connection is secured by TLS.
+func WithRequireTLS() Option {
+	return func(b *backend) {
+		b.requireTLS = true
+	}
+}
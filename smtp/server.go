@@ -1,19 +1,16 @@
 package smtp
 
 import (
-	"errors"
+	"crypto/tls"
 	"io"
 	"log/slog"
+	"net"
 
+	"github.com/emersion/go-sasl"
 	"github.com/emersion/go-smtp"
 	"github.com/zen-en-tonal/mtw/session"
 )
 
-var (
-	// Empty error to hide internal message.
-	Err error = errors.New("")
-)
-
 // New returns a smtp server.
 func New(options ...Option) *smtp.Server {
 	backend := backend{
@@ -22,7 +19,14 @@ func New(options ...Option) *smtp.Server {
 	for _, opt := range options {
 		opt(&backend)
 	}
-	return smtp.NewServer(backend)
+	server := smtp.NewServer(backend)
+	server.TLSConfig = backend.tlsConfig
+	server.EnableAuth(sasl.Login, func(c *smtp.Conn) sasl.Server {
+		return sasl.NewLoginServer(func(username, password string) error {
+			return c.Session().AuthPlain(username, password)
+		})
+	})
+	return server
 }
 
 type Logger interface {
@@ -33,30 +37,54 @@ type Logger interface {
 type Option func(*backend)
 
 type backend struct {
-	logger  Logger
-	options []session.Option
+	logger     Logger
+	options    []session.Option
+	tlsConfig  *tls.Config
+	requireTLS bool
 }
 
+// NewSession is called on every connection and again after STARTTLS.
 func (b backend) NewSession(c *smtp.Conn) (smtp.Session, error) {
-	s := session.New(b.options...)
-	return &smtpSession{s, b.logger}, nil
+	_, isTLS := c.TLSConnectionState()
+	client := session.Client{Helo: c.Hostname(), TLS: isTLS}
+	if addr, ok := c.Conn().RemoteAddr().(*net.TCPAddr); ok {
+		client.IP = addr.IP
+	}
+	options := append([]session.Option{session.WithClient(client)}, b.options...)
+	return &smtpSession{
+		inner:      session.New(options...),
+		logger:     b.logger,
+		tls:        isTLS,
+		requireTLS: b.requireTLS,
+	}, nil
 }
 
 type smtpSession struct {
-	inner  session.Session
-	logger Logger
+	inner      session.Session
+	logger     Logger
+	tls        bool
+	requireTLS bool
 }
 
+// AuthPlain authenticates the client by PLAIN and LOGIN.
 func (s *smtpSession) AuthPlain(username, password string) error {
-	s.logger.Info("AUTH", "msg", "someone try to login", "session_id", s.inner.ID())
-	return smtp.ErrAuthUnsupported
+	s.logger.Info("AUTH", "username", username, "session_id", s.inner.ID())
+	if err := s.inner.Login(username, password); err != nil {
+		s.logger.Error("AUTH", "inner", err, "username", username, "session_id", s.inner.ID())
+		return authError(err)
+	}
+	return nil
 }
 
 func (s *smtpSession) Mail(from string, opts *smtp.MailOptions) error {
 	s.logger.Info("MAIL", "from", from, "session_id", s.inner.ID())
+	if s.requireTLS && !s.tls {
+		s.logger.Error("MAIL", "inner", "tls is required", "from", from, "session_id", s.inner.ID())
+		return errTLSRequired
+	}
 	if err := s.inner.SetMail(from); err != nil {
 		s.logger.Error("MAIL", "inner", err, "from", from, "session_id", s.inner.ID())
-		return Err
+		return mailError(err)
 	}
 	return nil
 }
@@ -65,7 +93,7 @@ func (s *smtpSession) Rcpt(to string, opts *smtp.RcptOptions) error {
 	s.logger.Info("RCPT", "to", to, "session_id", s.inner.ID())
 	if err := s.inner.SetRcpt(to); err != nil {
 		s.logger.Error("RCPT", "inner", err, "to", to, "session_id", s.inner.ID())
-		return Err
+		return rcptError(err)
 	}
 	return nil
 }
@@ -75,7 +103,7 @@ func (s *smtpSession) Data(r io.Reader) error {
 	s.inner.SetData(r)
 	if err := s.inner.Commit(); err != nil {
 		s.logger.Error("DATA", "inner", err, "session_id", s.inner.ID())
-		return Err
+		return dataError(err)
 	}
 	return nil
 }
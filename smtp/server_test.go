@@ -0,0 +1,132 @@
+package smtp
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-sasl"
+	"github.com/emersion/go-smtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+type passwords map[string]string
+
+func (p passwords) Authenticate(username string, password string) error {
+	if p[username] != password {
+		return session.ErrAuthFailed
+	}
+	return nil
+}
+
+func serveTLS(t *testing.T, options ...Option) string {
+	certFile, keyFile := writeCert(t, t.TempDir(), "mx.mail.com")
+	r, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := New(append(options, WithTLS(r.TLSConfig()))...)
+	server.Domain = "mx.mail.com"
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	t.Cleanup(func() { server.Close() })
+	return l.Addr().String()
+}
+
+func TestAuth(t *testing.T) {
+	addr := serveTLS(t, WithSessionOptions(
+		session.WithAuthenticator(passwords{"service": "pass"}),
+	))
+	tests := []struct {
+		name   string
+		client sasl.Client
+		err    bool
+	}{
+		{"plain", sasl.NewPlainClient("", "service", "pass"), false},
+		{"login", sasl.NewLoginClient("service", "pass"), false},
+		{"wrong password", sasl.NewPlainClient("", "service", "wrong"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := smtp.Dial(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			if err := c.StartTLS(&tls.Config{InsecureSkipVerify: true}); err != nil {
+				t.Fatal(err)
+			}
+			err = c.Auth(tt.client)
+			if tt.err {
+				assert.ErrorContains(t, err, "535")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+type hookFunc func(t session.Transaction) error
+
+func (f hookFunc) Send(t session.Transaction) error {
+	return f(t)
+}
+
+func TestClient(t *testing.T) {
+	received := make(chan session.Transaction, 1)
+	addr := serveTLS(t, WithSessionOptions(
+		session.WithHooksAll(hookFunc(func(t session.Transaction) error {
+			received <- t
+			return nil
+		})),
+	))
+	c, err := smtp.Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := c.Hello("client.mail.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StartTLS(&tls.Config{InsecureSkipVerify: true}); err != nil {
+		t.Fatal(err)
+	}
+	body := "From: alice@mail.com\r\nTo: bob@mail.com\r\nSubject: hi\r\n\r\nhello\r\n"
+	if err := c.SendMail("alice@mail.com", []string{"bob@mail.com"}, strings.NewReader(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	trans := <-received
+	assert.Equal(t, "127.0.0.1", trans.RemoteAddr())
+	assert.Equal(t, "client.mail.com", trans.Helo())
+	assert.True(t, trans.TLS())
+}
+
+type brokenFilterSet struct{}
+
+func (f brokenFilterSet) FindFilters(_ session.Address) ([]session.Filter, error) {
+	return nil, errors.New("sql error")
+}
+
+func TestClient_TemporaryFailure(t *testing.T) {
+	addr := serveTLS(t, WithSessionOptions(
+		session.WithFilters(session.AsFilter(brokenFilterSet{})),
+	))
+	c, err := smtp.Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := c.StartTLS(&tls.Config{InsecureSkipVerify: true}); err != nil {
+		t.Fatal(err)
+	}
+	body := "From: alice@mail.com\r\nTo: bob@mail.com\r\nSubject: hi\r\n\r\nhello\r\n"
+	err = c.SendMail("alice@mail.com", []string{"bob@mail.com"}, strings.NewReader(body))
+	assert.ErrorContains(t, err, "451")
+}
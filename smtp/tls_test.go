@@ -0,0 +1,106 @@
+package smtp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	ns "net/smtp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCert writes a self-signed certificate for the name into the dir.
+func writeCert(t *testing.T, dir string, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, r *CertReloader) string {
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "old.mail.com")
+	r, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "old.mail.com", commonName(t, r))
+
+	writeCert(t, dir, "new.mail.com")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	assert.Equal(t, "new.mail.com", commonName(t, r))
+
+	// a broken renewal keeps the previous certificate.
+	os.WriteFile(certFile, []byte("broken"), 0600)
+	later = later.Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	assert.Equal(t, "new.mail.com", commonName(t, r))
+}
+
+func TestNewCertReloader_Missing(t *testing.T) {
+	_, err := NewCertReloader("missing.pem", "missing.pem")
+	assert.Error(t, err)
+}
+
+func TestRequireTLS(t *testing.T) {
+	addr := serveTLS(t, WithRequireTLS())
+	c, err := ns.Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ok, _ := c.Extension("STARTTLS")
+	assert.True(t, ok)
+	assert.ErrorContains(t, c.Mail("alice@mail.com"), "530")
+
+	if err := c.StartTLS(&tls.Config{ServerName: "mx.mail.com", InsecureSkipVerify: true}); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, c.Mail("alice@mail.com"))
+}
@@ -0,0 +1,215 @@
+package spam
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+	"time"
+
+	"blitiri.com.ar/go/spf"
+	"github.com/emersion/go-msgauth/dkim"
+	"github.com/emersion/go-msgauth/dmarc"
+	"github.com/zen-en-tonal/mtw/session"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Resolver looks up DNS records. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// maxSignatures is the number of DKIM signatures verified per mail.
+const maxSignatures = 5
+
+type authFilter struct {
+	resolver Resolver
+	timeout  time.Duration
+}
+
+// AuthOption configures the filter returned by AuthFilter.
+type AuthOption func(*authFilter)
+
+// WithResolver sets the Resolver to look up SPF, DKIM and DMARC records.
+// The default is net.DefaultResolver.
+func WithResolver(r Resolver) AuthOption {
+	return func(f *authFilter) {
+		f.resolver = r
+	}
+}
+
+// WithLookupTimeout sets the time limit of the lookups for a mail.
+func WithLookupTimeout(d time.Duration) AuthOption {
+	return func(f *authFilter) {
+		f.timeout = d
+	}
+}
+
+// AuthFilter returns a filter that checks SPF, DKIM and DMARC.
+// It is also a session.Verifier, so that the results are set on the Transaction.
+// The filter refuses mails that fail DMARC of a domain publishing `p=reject`.
+func AuthFilter(options ...AuthOption) authFilter {
+	f := authFilter{
+		resolver: net.DefaultResolver,
+		timeout:  time.Second * 5,
+	}
+	for _, opt := range options {
+		opt(&f)
+	}
+	return f
+}
+
+func (f authFilter) Validate(e session.Transaction) error {
+	results := e.AuthResults()
+	if !results.Verified() {
+		results = f.Verify(e)
+	}
+	if results.DMARC == session.AuthFail && results.DMARCPolicy == string(dmarc.PolicyReject) {
+		return fmt.Errorf("%w: dmarc of %s is failed: %s", session.ErrValidation, results.FromDomain, results.String())
+	}
+	return nil
+}
+
+// Verify checks SPF, DKIM and DMARC of the Transaction.
+func (f authFilter) Verify(e session.Transaction) session.AuthResults {
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	var results session.AuthResults
+	results.SPF, results.SPFDomain = f.spf(ctx, e)
+	results.DKIM, results.DKIMDomains = f.dkim(ctx, e)
+	results.DMARC, results.DMARCPolicy, results.FromDomain = f.dmarc(ctx, e, results)
+	return results
+}
+
+func (f authFilter) spf(ctx context.Context, e session.Transaction) (session.AuthResult, string) {
+	domain := e.Helo()
+	if addr, err := session.ParseAddr(e.SenderAddress()); err == nil {
+		domain = addr.Domain()
+	}
+	if e.ClientIP() == nil {
+		return session.AuthNone, domain
+	}
+	result, _ := spf.CheckHostWithSender(
+		e.ClientIP(),
+		e.Helo(),
+		e.SenderAddress(),
+		spf.WithContext(ctx),
+		spf.WithResolver(f.resolver),
+	)
+	return session.AuthResult(result), domain
+}
+
+func (f authFilter) dkim(ctx context.Context, e session.Transaction) (session.AuthResult, []string) {
+	verifications, err := dkim.VerifyWithOptions(bytes.NewReader(e.Raw()), &dkim.VerifyOptions{
+		LookupTXT:        f.lookupTXT(ctx),
+		MaxVerifications: maxSignatures,
+	})
+	if err != nil && len(verifications) == 0 {
+		return session.AuthPermError, nil
+	}
+	if len(verifications) == 0 {
+		return session.AuthNone, nil
+	}
+	result := session.AuthFail
+	var domains []string
+	for _, v := range verifications {
+		switch {
+		case v.Err == nil:
+			result = session.AuthPass
+			domains = append(domains, strings.ToLower(v.Domain))
+		case result == session.AuthPass:
+		case dkim.IsTempFail(v.Err):
+			result = session.AuthTempError
+		case dkim.IsPermFail(v.Err) && result != session.AuthTempError:
+			result = session.AuthPermError
+		}
+	}
+	return result, domains
+}
+
+// dmarc evaluates the alignment of SPF and DKIM with the domain of From.
+// Returns the result, the policy and the domain of From.
+func (f authFilter) dmarc(ctx context.Context, e session.Transaction, results session.AuthResults) (session.AuthResult, string, string) {
+	from, err := mail.ParseAddress(e.From())
+	if err != nil {
+		return session.AuthPermError, "", ""
+	}
+	at := strings.LastIndex(from.Address, "@")
+	if at < 0 {
+		return session.AuthPermError, "", ""
+	}
+	domain := strings.ToLower(from.Address[at+1:])
+
+	record, policy, err := f.lookupDMARC(ctx, domain)
+	if err == dmarc.ErrNoPolicy {
+		return session.AuthNone, "", domain
+	}
+	if dmarc.IsTempFail(err) {
+		return session.AuthTempError, "", domain
+	}
+	if err != nil {
+		return session.AuthPermError, "", domain
+	}
+
+	if results.SPF == session.AuthPass && aligned(results.SPFDomain, domain, record.SPFAlignment) {
+		return session.AuthPass, string(policy), domain
+	}
+	for _, d := range results.DKIMDomains {
+		if aligned(d, domain, record.DKIMAlignment) {
+			return session.AuthPass, string(policy), domain
+		}
+	}
+	return session.AuthFail, string(policy), domain
+}
+
+// lookupDMARC looks up the record of the domain, or of its organizational domain.
+// Returns the record and the policy applied to the domain.
+func (f authFilter) lookupDMARC(ctx context.Context, domain string) (*dmarc.Record, dmarc.Policy, error) {
+	options := &dmarc.LookupOptions{LookupTXT: f.lookupTXT(ctx)}
+	record, err := dmarc.LookupWithOptions(domain, options)
+	if err == nil {
+		return record, record.Policy, nil
+	}
+	org := organizational(domain)
+	if err != dmarc.ErrNoPolicy || org == domain {
+		return nil, "", err
+	}
+	record, err = dmarc.LookupWithOptions(org, options)
+	if err != nil {
+		return nil, "", err
+	}
+	if record.SubdomainPolicy != "" {
+		return record, record.SubdomainPolicy, nil
+	}
+	return record, record.Policy, nil
+}
+
+func (f authFilter) lookupTXT(ctx context.Context) func(string) ([]string, error) {
+	return func(domain string) ([]string, error) {
+		return f.resolver.LookupTXT(ctx, domain)
+	}
+}
+
+// aligned reports whether the domain is aligned with the domain of From.
+func aligned(domain string, from string, mode dmarc.AlignmentMode) bool {
+	domain = strings.ToLower(domain)
+	if mode == dmarc.AlignmentStrict {
+		return domain == from
+	}
+	return organizational(domain) == organizational(from)
+}
+
+// organizational returns the organizational domain, e.g. `mail.com` of `a.mail.com`.
+func organizational(domain string) string {
+	org, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return domain
+	}
+	return org
+}
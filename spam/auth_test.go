@@ -0,0 +1,181 @@
+package spam
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-msgauth/dkim"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+// fakeResolver answers TXT records only.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if txt, ok := r[strings.TrimSuffix(name, ".")]; ok {
+		return txt, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupAddr(_ context.Context, addr string) ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+}
+
+const authMail = "From: alice <alice@mail.com>\r\nTo: bob <bob@example.com>\r\nSubject: hello\r\n\r\nhello\r\n"
+
+func newResolver(t *testing.T) (fakeResolver, ed25519.PrivateKey) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fakeResolver{
+		"mail.com":                   {"v=spf1 ip4:192.0.2.1 -all"},
+		"sel._domainkey.mail.com":    {"v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(pub)},
+		"_dmarc.mail.com":            {"v=DMARC1; p=reject"},
+		"other.com":                  {"v=spf1 ip4:198.51.100.1 -all"},
+		"_dmarc.permissive.com":      {"v=DMARC1; p=none"},
+		"permissive.com":             {"v=spf1 -all"},
+		"sel._domainkey.unknown.com": {"v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(pub)},
+	}, key
+}
+
+func sign(t *testing.T, key ed25519.PrivateKey, domain string, message string) string {
+	var b bytes.Buffer
+	if err := dkim.Sign(&b, strings.NewReader(message), &dkim.SignOptions{
+		Domain:   domain,
+		Selector: "sel",
+		Signer:   key,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func newTransaction(t *testing.T, ip string, sender string, message string) session.Transaction {
+	s := session.New(session.WithClient(session.Client{IP: net.ParseIP(ip), Helo: "mx.mail.com"}))
+	if err := s.SetMail(sender); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetRcpt("bob@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	s.SetData(strings.NewReader(message))
+	trans, err := s.IntoTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *trans
+}
+
+func TestAuthFilter_Pass(t *testing.T) {
+	resolver, key := newResolver(t)
+	filter := AuthFilter(WithResolver(resolver))
+	trans := newTransaction(t, "192.0.2.1", "alice@mail.com", sign(t, key, "mail.com", authMail))
+
+	results := filter.Verify(trans)
+	assert.Equal(t, session.AuthPass, results.SPF)
+	assert.Equal(t, session.AuthPass, results.DKIM)
+	assert.Equal(t, []string{"mail.com"}, results.DKIMDomains)
+	assert.Equal(t, session.AuthPass, results.DMARC)
+	assert.Equal(t, "reject", results.DMARCPolicy)
+	assert.Equal(t, "spf=pass smtp.mailfrom=mail.com; dkim=pass header.d=mail.com; dmarc=pass header.from=mail.com", results.String())
+	assert.NoError(t, filter.Validate(trans))
+}
+
+func TestAuthFilter_AlignedByDKIM(t *testing.T) {
+	resolver, key := newResolver(t)
+	filter := AuthFilter(WithResolver(resolver))
+	// forwarded by other.com, so SPF is not aligned but the signature survives.
+	trans := newTransaction(t, "198.51.100.1", "bounce@other.com", sign(t, key, "mail.com", authMail))
+
+	results := filter.Verify(trans)
+	assert.Equal(t, session.AuthPass, results.SPF)
+	assert.Equal(t, "other.com", results.SPFDomain)
+	assert.Equal(t, session.AuthPass, results.DMARC)
+}
+
+func TestAuthFilter_Spoofed(t *testing.T) {
+	resolver, key := newResolver(t)
+	filter := AuthFilter(WithResolver(resolver))
+	trans := newTransaction(t, "203.0.113.1", "alice@mail.com", sign(t, key, "unknown.com", authMail))
+
+	results := filter.Verify(trans)
+	assert.Equal(t, session.AuthFail, results.SPF)
+	assert.Equal(t, session.AuthPass, results.DKIM)
+	assert.Equal(t, session.AuthFail, results.DMARC)
+	assert.ErrorIs(t, filter.Validate(trans), session.ErrValidation)
+}
+
+func TestAuthFilter_TamperedBody(t *testing.T) {
+	resolver, key := newResolver(t)
+	filter := AuthFilter(WithResolver(resolver))
+	signed := strings.Replace(sign(t, key, "mail.com", authMail), "hello\r\n\r\n", "hello\r\n\r\ngoodbye", 1)
+	trans := newTransaction(t, "203.0.113.1", "alice@mail.com", signed)
+
+	results := filter.Verify(trans)
+	assert.Equal(t, session.AuthFail, results.DKIM)
+	assert.Equal(t, session.AuthFail, results.DMARC)
+	assert.ErrorIs(t, filter.Validate(trans), session.ErrValidation)
+}
+
+func TestAuthFilter_PolicyNone(t *testing.T) {
+	resolver, _ := newResolver(t)
+	filter := AuthFilter(WithResolver(resolver))
+	message := strings.Replace(authMail, "alice@mail.com", "alice@permissive.com", 1)
+	trans := newTransaction(t, "203.0.113.1", "alice@permissive.com", message)
+
+	results := filter.Verify(trans)
+	assert.Equal(t, session.AuthFail, results.SPF)
+	assert.Equal(t, session.AuthNone, results.DKIM)
+	assert.Equal(t, session.AuthFail, results.DMARC)
+	assert.Equal(t, "none", results.DMARCPolicy)
+	assert.NoError(t, filter.Validate(trans))
+}
+
+func TestAuthFilter_OnSession(t *testing.T) {
+	resolver, _ := newResolver(t)
+	filter := AuthFilter(WithResolver(resolver))
+	var got session.Transaction
+	s := session.New(
+		session.WithClient(session.Client{IP: net.ParseIP("192.0.2.1"), Helo: "mx.mail.com"}),
+		session.WithVerifier(filter),
+		session.WithFilters(filter),
+		session.WithHooksAll(hookFunc(func(t session.Transaction) error {
+			got = t
+			return nil
+		})),
+	)
+	if err := s.SetMail("alice@mail.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetRcpt("bob@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	s.SetData(strings.NewReader(authMail))
+	if err := s.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, session.AuthPass, got.AuthResults().DMARC)
+}
+
+type hookFunc func(t session.Transaction) error
+
+func (f hookFunc) Send(t session.Transaction) error {
+	return f(t)
+}
@@ -9,6 +9,7 @@ import (
 )
 
 // RcptMismatchFilter returns a filter that compares `rcpt` and `to`.
+// Every recipient of the envelope has to be listed in `to`.
 func RcptMismatchFilter() rcptMismatchFilter {
 	return rcptMismatchFilter{}
 }
@@ -16,13 +17,20 @@ func RcptMismatchFilter() rcptMismatchFilter {
 type rcptMismatchFilter struct{}
 
 func (r rcptMismatchFilter) Validate(e session.Transaction) error {
-	rcpt := e.RcptAddress()
-	to, err := mail.ParseAddress(e.To())
+	to, err := mail.ParseAddressList(e.To())
 	if err != nil {
-		return session.ErrNilEnvelope
+		return fmt.Errorf("%w: to %s is malformed", session.ErrValidation, e.To())
 	}
-	if rcpt != to.Address {
-		return fmt.Errorf("rcpt %s and to %s is mismatched", rcpt, to.Address)
+	listed := make(map[string]bool, len(to))
+	for _, addr := range to {
+		if a, err := session.ParseAddr(addr.Address); err == nil {
+			listed[a.String()] = true
+		}
+	}
+	for _, rcpt := range e.Rcpts() {
+		if !listed[rcpt.String()] {
+			return fmt.Errorf("%w: rcpt %s and to %s is mismatched", session.ErrValidation, rcpt.String(), e.To())
+		}
 	}
 	return nil
 }
@@ -36,21 +44,26 @@ func BlackListFilter(patterns ...string) blackList {
 }
 
 func (patterns blackList) Validate(e session.Transaction) error {
-	rcpt := e.RcptAddress()
-	to, err := mail.ParseAddress(e.To())
+	to, err := mail.ParseAddressList(e.To())
 	if err != nil {
-		return session.ErrNilEnvelope
+		return fmt.Errorf("%w: to %s is malformed", session.ErrValidation, e.To())
+	}
+	addrs := make([]string, 0, len(e.Rcpts())+len(to))
+	for _, rcpt := range e.Rcpts() {
+		addrs = append(addrs, rcpt.String())
+	}
+	for _, addr := range to {
+		addrs = append(addrs, addr.Address)
 	}
 	for _, pattern := range patterns {
 		r, err := regexp.Compile(pattern)
 		if err != nil {
 			return err
 		}
-		if r.Match([]byte(rcpt)) {
-			return fmt.Errorf("addr %s contains blacklist", rcpt)
-		}
-		if r.Match([]byte(to.Address)) {
-			return fmt.Errorf("addr %s contains blacklist", to.Address)
+		for _, addr := range addrs {
+			if r.Match([]byte(addr)) {
+				return fmt.Errorf("%w: addr %s contains blacklist", session.ErrValidation, addr)
+			}
 		}
 	}
 	return nil
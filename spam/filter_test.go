@@ -81,3 +81,24 @@ func TestBlacklist_Pass(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestRcptMismatchFilter_Multiple_Rcpts(t *testing.T) {
+	session := session.New(
+		session.WithFilters(RcptMismatchFilter()),
+	)
+	if err := session.SetMail("alice<alice@mail.com>"); err != nil {
+		panic(err)
+	}
+	for _, rcpt := range []string{"bob<bob@mail.com>", "tom<tom@mail.com>"} {
+		if err := session.SetRcpt(rcpt); err != nil {
+			panic(err)
+		}
+	}
+	header := "From: alice<alice@mail.com>\nTo: bob<bob@mail.com>, tom<tom@mail.com>\nSubject: Subject\n\n"
+	if err := session.SetData(strings.NewReader(header + "hello")); err != nil {
+		panic(err)
+	}
+	if err := session.Commit(); err != nil {
+		t.Error(err)
+	}
+}
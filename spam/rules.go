@@ -0,0 +1,95 @@
+package spam
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+var ErrInvalidRule = errors.New("invalid rule")
+
+// Action is what a Rule does with the senders it matches.
+type Action string
+
+const (
+	Allow Action = "allow"
+	Deny  Action = "deny"
+)
+
+// Rule matches senders by address or domain.
+// The pattern is a glob, e.g. `alice@mail.com`, `*@github.com` or `*.github.com`.
+// A pattern without `@` matches the domain, so `github.com` is `*@github.com`.
+type Rule struct {
+	Action  Action
+	Pattern string
+}
+
+// NewRule returns a Rule with the normalized pattern.
+//
+// # Errors
+//   - ErrInvalidRule if the action is unknown or the pattern is malformed.
+func NewRule(action string, pattern string) (*Rule, error) {
+	a := Action(strings.ToLower(action))
+	if a != Allow && a != Deny {
+		return nil, fmt.Errorf("%w: unknown action %s", ErrInvalidRule, action)
+	}
+	p := strings.ToLower(strings.TrimSpace(pattern))
+	if p == "" {
+		return nil, fmt.Errorf("%w: empty pattern", ErrInvalidRule)
+	}
+	if !strings.Contains(p, "@") {
+		p = "*@" + p
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+	return &Rule{Action: a, Pattern: p}, nil
+}
+
+// Match reports whether the sender matches the pattern.
+func (r Rule) Match(sender string) bool {
+	ok, _ := path.Match(r.Pattern, strings.ToLower(sender))
+	return ok
+}
+
+type senderRules []Rule
+
+// SenderRulesFilter returns a filter that checks the sender by the Rules.
+// A sender matching a Deny rule is refused.
+// If at least one Allow rule exists, a sender matching none of them is refused too.
+func SenderRulesFilter(rules ...Rule) senderRules {
+	return senderRules(rules)
+}
+
+func (rules senderRules) Validate(e session.Transaction) error {
+	if err := rules.Accept(e.SenderAddress()); err != nil {
+		return fmt.Errorf("%w: %w", session.ErrValidation, err)
+	}
+	return nil
+}
+
+// Accept checks the sender by the Rules.
+//
+// # Errors
+//   - session.ErrSenderDenied if the Rules refuse the sender.
+func (rules senderRules) Accept(sender string) error {
+	allowed, hasAllow := false, false
+	for _, rule := range rules {
+		switch rule.Action {
+		case Deny:
+			if rule.Match(sender) {
+				return fmt.Errorf("%w: %s by %s", session.ErrSenderDenied, sender, rule.Pattern)
+			}
+		case Allow:
+			hasAllow = true
+			allowed = allowed || rule.Match(sender)
+		}
+	}
+	if hasAllow && !allowed {
+		return fmt.Errorf("%w: %s is not allowed", session.ErrSenderDenied, sender)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package spam
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+func rules(t *testing.T, xs ...[2]string) []Rule {
+	var rs []Rule
+	for _, x := range xs {
+		r, err := NewRule(x[0], x[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, *r)
+	}
+	return rs
+}
+
+func TestNewRule(t *testing.T) {
+	r, err := NewRule("allow", "GitHub.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "*@github.com", r.Pattern)
+
+	_, err = NewRule("block", "github.com")
+	assert.ErrorIs(t, err, ErrInvalidRule)
+	_, err = NewRule("deny", "[github.com")
+	assert.ErrorIs(t, err, ErrInvalidRule)
+}
+
+func TestSenderRulesFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  [][2]string
+		sender string
+		err    bool
+	}{
+		{"no rules", nil, "alice@mail.com", false},
+		{"allowed domain", [][2]string{{"allow", "*@github.com"}}, "noreply@github.com", false},
+		{"not allowed", [][2]string{{"allow", "*@github.com"}}, "alice@mail.com", true},
+		{"subdomain", [][2]string{{"allow", "*.github.com"}}, "noreply@ci.github.com", false},
+		{"denied address", [][2]string{{"deny", "spam@mail.com"}}, "spam@mail.com", true},
+		{"deny wins", [][2]string{{"allow", "mail.com"}, {"deny", "spam@mail.com"}}, "spam@mail.com", true},
+		{"deny others", [][2]string{{"deny", "spam@mail.com"}}, "alice@mail.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := session.New(session.WithFilters(SenderRulesFilter(rules(t, tt.rules...)...)))
+			if err := s.SetMail(tt.sender); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.SetRcpt("bob@mail.com"); err != nil {
+				t.Fatal(err)
+			}
+			s.SetData(createMail("hello"))
+			err := s.Commit()
+			if tt.err {
+				assert.ErrorIs(t, err, session.ErrValidation)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidScope = errors.New("invalid scope")
+	ErrInvalidToken = errors.New("invalid token")
+	ErrEmptyOwner   = errors.New("owner must not be empty unless the token is admin")
+)
+
+// Scope is what a Token is allowed to do.
+type Scope string
+
+const (
+	// Read allows to get the resources of the owner.
+	Read Scope = "read"
+	// Write allows to change the resources of the owner, and implies Read.
+	Write Scope = "write"
+	// Admin allows everything on the resources of every owner,
+	// and to manage domains, SMTP credentials and tokens.
+	Admin Scope = "admin"
+)
+
+// ParseScopes returns the Scopes named in `scopes`.
+//
+// # Errors
+//   - ErrInvalidScope if a scope is unknown or none is given.
+func ParseScopes(scopes []string) ([]Scope, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: no scope", ErrInvalidScope)
+	}
+	parsed := make([]Scope, len(scopes))
+	for i, s := range scopes {
+		scope := Scope(strings.ToLower(strings.TrimSpace(s)))
+		if scope != Read && scope != Write && scope != Admin {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, s)
+		}
+		parsed[i] = scope
+	}
+	return parsed, nil
+}
+
+// Token is an API token of an owner. Only the hash of the secret is kept.
+type Token struct {
+	ID        uuid.UUID
+	Name      string
+	Owner     string // the tenant owning the resources the Token creates.
+	Scopes    []Scope
+	ExpiresAt *time.Time // nil never expires.
+	CreatedAt time.Time
+}
+
+// Allows reports whether the Token has the Scope.
+// Admin implies every Scope and Write implies Read.
+func (t Token) Allows(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if s == scope || s == Admin || (s == Write && scope == Read) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin reports whether the Token has the Admin Scope.
+func (t Token) IsAdmin() bool {
+	return t.Allows(Admin)
+}
+
+// Validate refuses a Token scoped to no owner, since the resources made
+// before owners existed and by the bootstrap secret belong to the empty owner.
+//
+// # Errors
+//   - ErrEmptyOwner if the Token is not admin and its owner is empty.
+func (t Token) Validate() error {
+	if !t.IsAdmin() && strings.TrimSpace(t.Owner) == "" {
+		return ErrEmptyOwner
+	}
+	return nil
+}
+
+// Expired reports whether the Token is expired at `now`.
+func (t Token) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// prefix marks the secrets of Tokens to be found by secret scanners.
+const prefix = "mtw_"
+
+// NewSecret returns a random secret for a Token.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// Hash returns the hash of the secret to store and look up.
+// Secrets are random enough that a fast hash does not weaken them.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
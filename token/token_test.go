@@ -0,0 +1,61 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken_Allows(t *testing.T) {
+	read := Token{Scopes: []Scope{Read}}
+	assert.True(t, read.Allows(Read))
+	assert.False(t, read.Allows(Write))
+	assert.False(t, read.IsAdmin())
+
+	write := Token{Scopes: []Scope{Write}}
+	assert.True(t, write.Allows(Read))
+	assert.True(t, write.Allows(Write))
+	assert.False(t, write.Allows(Admin))
+
+	admin := Token{Scopes: []Scope{Admin}}
+	assert.True(t, admin.Allows(Read))
+	assert.True(t, admin.Allows(Write))
+	assert.True(t, admin.IsAdmin())
+}
+
+func TestToken_Validate(t *testing.T) {
+	assert.NoError(t, Token{Owner: "alice", Scopes: []Scope{Write}}.Validate())
+	assert.NoError(t, Token{Scopes: []Scope{Admin}}.Validate())
+	assert.ErrorIs(t, Token{Scopes: []Scope{Read}}.Validate(), ErrEmptyOwner)
+	assert.ErrorIs(t, Token{Owner: " ", Scopes: []Scope{Write}}.Validate(), ErrEmptyOwner)
+}
+
+func TestToken_Expired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	assert.False(t, Token{}.Expired(now))
+	assert.True(t, Token{ExpiresAt: &past}.Expired(now))
+	assert.True(t, Token{ExpiresAt: &now}.Expired(now))
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"read", " Write "})
+	assert.NoError(t, err)
+	assert.Equal(t, []Scope{Read, Write}, scopes)
+
+	_, err = ParseScopes([]string{"root"})
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	_, err = ParseScopes(nil)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	assert.NoError(t, err)
+	b, _ := NewSecret()
+	assert.NotEqual(t, a, b)
+	assert.Regexp(t, `^mtw_[0-9a-f]{64}$`, a)
+	assert.Equal(t, Hash(a), Hash(a))
+	assert.NotEqual(t, Hash(a), Hash(b))
+}
@@ -10,7 +10,9 @@ type Blueprint struct {
 	Method      string
 	Auth        string
 	Schema      string
+	SchemaMode  string // SchemaModeHTML or SchemaModeText. Empty means SchemaModeHTML.
 	ContentType string
+	Secret      string
 }
 
 func (b Blueprint) options(defaults ...Option) (*[]Option, error) {
@@ -27,17 +29,23 @@ func (b Blueprint) options(defaults ...Option) (*[]Option, error) {
 	}
 
 	if b.Schema != "" {
-		opt, err := WithSchema(b.Schema, b.ContentType)
+		opt, err := WithSchemaMode(b.Schema, b.SchemaMode, b.ContentType)
 		if err != nil {
 			return nil, err
 		}
 		options = append(options, opt)
+	} else if b.ContentType != "" {
+		options = append(options, WithContentType(b.ContentType))
 	}
 
 	if b.Auth != "" {
 		options = append(options, WithAuth(b.Auth))
 	}
 
+	if b.Secret != "" {
+		options = append(options, WithSecret(b.Secret))
+	}
+
 	options = append(options, WithMethod(b.Method))
 
 	return &options, nil
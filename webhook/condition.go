@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+var ErrInvalidCondition = errors.New("invalid condition")
+
+// Condition decides which Transactions are routed to a Webhook linked to an Address.
+// Empty fields match any Transaction, and every field set has to match.
+type Condition struct {
+	Subject      string // a regexp the subject matches.
+	Sender       string // a glob of the sender, e.g. `*@github.com`. A bare domain means `*@domain`.
+	HeaderName   string
+	HeaderValue  string // the value the header named HeaderName equals. If empty, the header only has to exist.
+	BodyContains string // a string the text or html body contains.
+}
+
+// IsZero reports whether the Condition matches any Transaction.
+func (c Condition) IsZero() bool {
+	return c == Condition{}
+}
+
+// Validate returns an error wrapping ErrInvalidCondition if the Condition is malformed.
+func (c Condition) Validate() error {
+	if _, err := regexp.Compile(c.Subject); err != nil {
+		return fmt.Errorf("%w: subject: %w", ErrInvalidCondition, err)
+	}
+	if _, err := path.Match(c.sender(), ""); err != nil {
+		return fmt.Errorf("%w: sender: %w", ErrInvalidCondition, err)
+	}
+	if c.HeaderName == "" && c.HeaderValue != "" {
+		return fmt.Errorf("%w: header value without name", ErrInvalidCondition)
+	}
+	return nil
+}
+
+// Match reports whether the Transaction satisfies the Condition.
+// A malformed Condition matches nothing.
+func (c Condition) Match(t session.Transaction) bool {
+	if c.Subject != "" {
+		r, err := regexp.Compile(c.Subject)
+		if err != nil || !r.MatchString(t.Subject()) {
+			return false
+		}
+	}
+	if c.Sender != "" {
+		ok, err := path.Match(c.sender(), strings.ToLower(t.SenderAddress()))
+		if err != nil || !ok {
+			return false
+		}
+	}
+	if c.HeaderName != "" {
+		values := t.Headers(c.HeaderName)
+		if len(values) == 0 || (c.HeaderValue != "" && strings.TrimSpace(values[0]) != c.HeaderValue) {
+			return false
+		}
+	}
+	if c.BodyContains != "" {
+		if !strings.Contains(t.Text(), c.BodyContains) && !strings.Contains(t.HTML(), c.BodyContains) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) sender() string {
+	s := strings.ToLower(strings.TrimSpace(c.Sender))
+	if s != "" && !strings.Contains(s, "@") {
+		return "*@" + s
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+func alertTransaction() session.Transaction {
+	mail := "From: alerts<alerts@monitor.com>\nTo: bob<bob@mail.com>\nSubject: [CRITICAL] disk full\nX-Priority: 1\n\nserver-01 is down"
+	t, err := session.NewTransaction(
+		uuid.New(),
+		session.MustParseAddr("alerts@monitor.com"),
+		session.MustParseAddr("bob@mail.com"),
+		strings.NewReader(mail),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return *t
+}
+
+func TestCondition_Match(t *testing.T) {
+	tests := []struct {
+		name  string
+		cond  Condition
+		match bool
+	}{
+		{"zero", Condition{}, true},
+		{"subject", Condition{Subject: `^\[CRITICAL\]`}, true},
+		{"subject mismatch", Condition{Subject: `^\[INFO\]`}, false},
+		{"sender domain", Condition{Sender: "monitor.com"}, true},
+		{"sender glob", Condition{Sender: "*@github.com"}, false},
+		{"header equals", Condition{HeaderName: "x-priority", HeaderValue: "1"}, true},
+		{"header differs", Condition{HeaderName: "X-Priority", HeaderValue: "5"}, false},
+		{"header exists", Condition{HeaderName: "X-Priority"}, true},
+		{"header missing", Condition{HeaderName: "X-Missing"}, false},
+		{"body", Condition{BodyContains: "is down"}, true},
+		{"all", Condition{Subject: "CRITICAL", Sender: "monitor.com", BodyContains: "up"}, false},
+	}
+	trans := alertTransaction()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, tt.cond.Match(trans))
+		})
+	}
+}
+
+func TestCondition_Validate(t *testing.T) {
+	assert.NoError(t, Condition{Subject: "CRITICAL"}.Validate())
+	assert.ErrorIs(t, Condition{Subject: "("}.Validate(), ErrInvalidCondition)
+	assert.ErrorIs(t, Condition{Sender: "[github.com"}.Validate(), ErrInvalidCondition)
+	assert.ErrorIs(t, Condition{HeaderValue: "1"}.Validate(), ErrInvalidCondition)
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxResponseLength is the number of bytes of a response body kept in a Delivery.
+const maxResponseLength = 4096
+
+// Delivery is a record of an attempt to send a Transaction.
+type Delivery struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	WebhookID     WebhookID
+	Address       string
+	BodyHash      string // hex encoded sha256 of the request body.
+	StatusCode    int    // 0 if no response was received.
+	Response      string // truncated response body.
+	Latency       time.Duration
+	Error         string
+	CreatedAt     time.Time
+}
+
+// Recorder records Deliveries.
+type Recorder interface {
+	// Record persists a Delivery.
+	// Returns an error if persisting fails.
+	Record(d Delivery) error
+}
+
+// nullRecorder always returns nil on Record.
+type nullRecorder struct{}
+
+func (r nullRecorder) Record(_ Delivery) error {
+	return nil
+}
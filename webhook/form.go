@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+// FormFileField is the name of the form field each attachment is uploaded as.
+const FormFileField string = "file"
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// isMultipart reports whether the Webhook sends multipart/form-data.
+func (w Webhook) isMultipart() bool {
+	mediatype, _, err := mime.ParseMediaType(w.header.Get("Content-Type"))
+	return err == nil && mediatype == ContentTypeMultipart
+}
+
+// formData returns a multipart/form-data body and its Content-Type with the boundary.
+// The schema, if any, has to render a JSON object whose members become the form fields,
+// and every attachment of the Transaction is uploaded as a file named FormFileField.
+func (w Webhook) formData(t session.Transaction) (io.Reader, string, error) {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	if w.schema != nil {
+		fields, err := formFields(*w.schema, t)
+		if err != nil {
+			return nil, "", err
+		}
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := mw.WriteField(k, fields[k]); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	for _, a := range t.Attachments() {
+		filename := a.Filename
+		if filename == "" {
+			filename = "attachment"
+		}
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(
+			`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(FormFileField),
+			quoteEscaper.Replace(filename),
+		))
+		h.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(a.Content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf, mw.FormDataContentType(), nil
+}
+
+// formFields renders the schema into a JSON object and returns its members as strings.
+// Members other than strings are kept in JSON.
+func formFields(s schema, t session.Transaction) (map[string]string, error) {
+	r, err := s.render(t, ContentTypeJson)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&members); err != nil {
+		return nil, SchemaError{fmt.Errorf("the schema of a multipart webhook has to render a JSON object: %w", err)}
+	}
+	fields := make(map[string]string, len(members))
+	for k, v := range members {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			fields[k] = s
+			continue
+		}
+		fields[k] = string(v)
+	}
+	return fields, nil
+}
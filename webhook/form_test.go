@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+const reportMail = "From: alice<alice@mail.com>\r\n" +
+	"To: bob<bob@mail.com>\r\n" +
+	"Subject: Daily report\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"b\"\r\n" +
+	"\r\n" +
+	"--b\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"see the attachment\r\n" +
+	"--b\r\n" +
+	"Content-Type: text/csv\r\n" +
+	"Content-Disposition: attachment; filename=\"report.csv\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"aWQsbmFtZQoxLGFsaWNlCg==\r\n" +
+	"--b--\r\n"
+
+func reportTransaction() session.Transaction {
+	t, err := session.NewTransaction(
+		uuid.New(),
+		session.MustParseAddr("alice@mail.com"),
+		session.MustParseAddr("bob@mail.com"),
+		strings.NewReader(reportMail),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return *t
+}
+
+func Test_TemplateAttachments(t *testing.T) {
+	wh, err := FromBlueprint(Blueprint{
+		Endpoint:    "http://example.local",
+		Method:      "POST",
+		Schema:      `[{{range .Attachments}}{"name":"{{.Filename}}","type":"{{.ContentType}}","size":{{.Size}},"content":"{{Base64 .Content}}"}{{end}}]`,
+		ContentType: ContentTypeJson,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := wh.PrepareRequest(reportTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	assert.Equal(t, `[{"name":"report.csv","type":"text/csv","size":16,"content":"aWQsbmFtZQoxLGFsaWNlCg=="}]`, string(body))
+}
+
+func Test_Multipart(t *testing.T) {
+	wh, err := FromBlueprint(Blueprint{
+		Endpoint:    "http://example.local",
+		Method:      "POST",
+		Schema:      `{"subject":"{{.Subject}}","count":{{len .Attachments}}}`,
+		ContentType: ContentTypeMultipart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := wh.PrepareRequest(reportTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediatype, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, ContentTypeMultipart, mediatype)
+
+	form, err := multipart.NewReader(req.Body, params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"Daily report"}, form.Value["subject"])
+	assert.Equal(t, []string{"1"}, form.Value["count"])
+	if assert.Len(t, form.File[FormFileField], 1) {
+		file := form.File[FormFileField][0]
+		assert.Equal(t, "report.csv", file.Filename)
+		assert.Equal(t, "text/csv", file.Header.Get("Content-Type"))
+		f, _ := file.Open()
+		content, _ := io.ReadAll(f)
+		assert.Equal(t, "id,name\n1,alice\n", string(content))
+	}
+	assert.Equal(t, ContentTypeMultipart, wh.IntoBlueprint().ContentType)
+}
+
+func Test_Multipart_NoSchema(t *testing.T) {
+	wh, err := FromBlueprint(Blueprint{
+		Endpoint:    "http://example.local",
+		Method:      "POST",
+		ContentType: ContentTypeMultipart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := wh.PrepareRequest(reportTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	form, err := multipart.NewReader(req.Body, params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, form.Value)
+	assert.Len(t, form.File[FormFileField], 1)
+}
+
+func Test_Multipart_NotObject(t *testing.T) {
+	wh, err := FromBlueprint(Blueprint{
+		Endpoint:    "http://example.local",
+		Method:      "POST",
+		Schema:      `{{.Subject}}`,
+		ContentType: ContentTypeMultipart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wh.PrepareRequest(reportTransaction())
+	assert.Error(t, err)
+}
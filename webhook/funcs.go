@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// textFuncs are the helpers of SchemaModeText.
+var textFuncs = map[string]interface{}{
+	"json":           toJSON,
+	"jsonString":     jsonString,
+	"base64":         toBase64,
+	"trim":           strings.TrimSpace,
+	"lower":          strings.ToLower,
+	"regexFind":      regexFind,
+	"default":        defaultValue,
+	"date":           date,
+	"htmlToMarkdown": htmlToMarkdown,
+	"limit":          limit,
+}
+
+// toJSON encodes v into a JSON value, e.g. `"a \"quoted\" text"` for a string.
+func toJSON(v any) (string, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// jsonString escapes s to be embedded between the quotes of a JSON string.
+func jsonString(s string) string {
+	quoted, _ := toJSON(s)
+	return quoted[1 : len(quoted)-1]
+}
+
+// toBase64 encodes a string or bytes in the standard base64.
+func toBase64(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return base64.StdEncoding.EncodeToString([]byte(v)), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	default:
+		return "", fmt.Errorf("base64: unsupported type %T", v)
+	}
+}
+
+// regexFind returns the leftmost match of the pattern in s, or an empty string.
+func regexFind(pattern string, s string) (string, error) {
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return r.FindString(s), nil
+}
+
+// defaultValue returns v unless it is empty, otherwise def.
+// Zero values, empty strings, slices and maps are empty.
+func defaultValue(def any, v any) any {
+	if v == nil {
+		return def
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return def
+		}
+	default:
+		if rv.IsZero() {
+			return def
+		}
+	}
+	return v
+}
+
+// date formats t with the layout of the time package, e.g. `2006-01-02`.
+// The zero time is formatted to an empty string.
+func date(layout string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	spaces     = regexp.MustCompile(`\s+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToMarkdown converts an HTML into Markdown, e.g. to post an HTML mail to chats.
+// Scripts and styles are dropped, and elements without a Markdown counterpart are unwrapped.
+func htmlToMarkdown(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+	md := markdown{}.node(doc)
+	lines := strings.Split(md, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	md = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(md)
+}
+
+// markdown converts nodes in the context.
+type markdown struct {
+	pre bool // inside a pre element, where the text is kept as is.
+}
+
+func (m markdown) node(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		if m.pre {
+			return n.Data
+		}
+		return spaces.ReplaceAllString(n.Data, " ")
+	case html.DocumentNode:
+		return m.children(n)
+	case html.ElementNode:
+		return m.element(n)
+	default:
+		return ""
+	}
+}
+
+func (m markdown) children(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(m.node(c))
+	}
+	return b.String()
+}
+
+func (m markdown) element(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Title:
+		return ""
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		return "\n\n---\n\n"
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + strings.TrimSpace(m.children(n)) + "\n\n"
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Table:
+		return "\n\n" + strings.TrimSpace(m.children(n)) + "\n\n"
+	case atom.Tr:
+		var cells []string
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.DataAtom == atom.Td || c.DataAtom == atom.Th {
+				cells = append(cells, strings.TrimSpace(m.children(c)))
+			}
+		}
+		return strings.Join(cells, " | ") + "\n"
+	case atom.Strong, atom.B:
+		return wrap("**", m.children(n))
+	case atom.Em, atom.I:
+		return wrap("_", m.children(n))
+	case atom.Del, atom.S:
+		return wrap("~~", m.children(n))
+	case atom.Code:
+		if m.pre {
+			return m.children(n)
+		}
+		return wrap("`", m.children(n))
+	case atom.Pre:
+		code := markdown{pre: true}.children(n)
+		return "\n\n```\n" + strings.Trim(code, "\n") + "\n```\n\n"
+	case atom.A:
+		text := strings.TrimSpace(m.children(n))
+		href := attr(n, "href")
+		if href == "" || text == href {
+			return text
+		}
+		if text == "" {
+			text = href
+		}
+		return fmt.Sprintf("[%s](%s)", text, href)
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" {
+			return ""
+		}
+		return fmt.Sprintf("![%s](%s)", attr(n, "alt"), src)
+	case atom.Ul, atom.Ol:
+		return "\n\n" + m.list(n) + "\n\n"
+	case atom.Blockquote:
+		quote := strings.TrimSpace(blankLines.ReplaceAllString(m.children(n), "\n\n"))
+		return "\n\n> " + strings.ReplaceAll(quote, "\n", "\n> ") + "\n\n"
+	default:
+		return m.children(n)
+	}
+}
+
+// list converts the items of an ul or ol element.
+// Nested blocks are indented under their item.
+func (m markdown) list(n *html.Node) string {
+	var items []string
+	i := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom != atom.Li {
+			continue
+		}
+		i++
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", i)
+		}
+		item := strings.TrimSpace(blankLines.ReplaceAllString(m.children(c), "\n\n"))
+		item = strings.ReplaceAll(item, "\n\n", "\n")
+		items = append(items, marker+strings.ReplaceAll(item, "\n", "\n"+strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// wrap surrounds the text with the mark, keeping the spaces around outside.
+func wrap(mark string, text string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := text[:strings.Index(text, trimmed)]
+	end := text[len(start)+len(trimmed):]
+	return start + mark + trimmed + mark + end
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
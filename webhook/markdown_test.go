@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"text", `hello`, "hello"},
+		{"paragraphs", `<p>one</p><p>two<br>three</p>`, "one\n\ntwo\nthree"},
+		{"heading", `<h2>Title</h2><p>body</p>`, "## Title\n\nbody"},
+		{"inline", `<p><b>bold</b> and <em>em</em> and <code>x := 1</code></p>`, "**bold** and _em_ and `x := 1`"},
+		{"link", `<a href="https://github.com">GitHub</a> <a href="https://a.com">https://a.com</a>`, "[GitHub](https://github.com) https://a.com"},
+		{"image", `<img src="cid:logo" alt="logo">`, "![logo](cid:logo)"},
+		{"list", `<ul><li>a</li><li>b<ol><li>c</li><li>d</li></ol></li></ul>`, "- a\n- b\n  1. c\n  2. d"},
+		{"quote", `<blockquote><p>one</p><p>two</p></blockquote>`, "> one\n>\n> two"},
+		{"pre", "<pre><code>func main() {\n\treturn\n}</code></pre>", "```\nfunc main() {\n\treturn\n}\n```"},
+		{"dropped", `<html><head><style>p {}</style></head><body><script>x()</script><div>  hello   world </div></body></html>`, "hello world"},
+		{"table", `<table><tr><th>a</th><th>b</th></tr><tr><td>1</td><td>2</td></tr></table>`, "a | b\n1 | 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, htmlToMarkdown(tt.html))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"encoding/base64"
 	"html"
 	"html/template"
 	"log/slog"
@@ -11,23 +12,27 @@ import (
 )
 
 var tmplFuncs = map[string]interface{}{
-	"Limit": func(max int, s string) string {
-		runes := []rune(s)
-		if len(runes) <= max {
-			return s
-		}
-		text := make([]rune, max)
-		for i, c := range runes {
-			if i >= max {
-				return string(text)
-			}
-			text[i] = c
-		}
-		return string(text)
-	},
+	"Limit": limit,
 	"Escape": func(s string) string {
 		return html.EscapeString(s)
 	},
+	// Base64 encodes bytes such as the content of an attachment.
+	// The alphabet is safe in any context, so the output is not escaped.
+	"Base64": func(b []byte) template.HTML {
+		return template.HTML(base64.StdEncoding.EncodeToString(b))
+	},
+}
+
+// limit truncates s to max runes. A negative max is taken as 0.
+func limit(max int, s string) string {
+	if max < 0 {
+		max = 0
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
 }
 
 func WithMethod(method string) Option {
@@ -36,8 +41,18 @@ func WithMethod(method string) Option {
 	}
 }
 
+// WithSchema sets a schema rendered with html/template.
 func WithSchema(schema string, contentType string) (Option, error) {
-	s, err := template.New("").Funcs(tmplFuncs).Parse(schema)
+	return WithSchemaMode(schema, "", contentType)
+}
+
+// WithSchemaMode sets a schema rendered in the mode, SchemaModeHTML or SchemaModeText.
+// An empty mode means SchemaModeHTML.
+//
+// # Errors
+//   - ErrInvalidSchema if the mode is unknown or the schema is malformed.
+func WithSchemaMode(schema string, mode string, contentType string) (Option, error) {
+	s, err := parseSchema(schema, mode)
 	if err != nil {
 		return nil, err
 	}
@@ -47,12 +62,28 @@ func WithSchema(schema string, contentType string) (Option, error) {
 	}, nil
 }
 
+// WithContentType sets the Content-Type of requests without a schema.
+// With ContentTypeMultipart, the attachments are uploaded as files.
+func WithContentType(contentType string) Option {
+	return func(w *Webhook) {
+		w.header.Set("Content-Type", contentType)
+	}
+}
+
 func WithAuth(token string) Option {
 	return func(w *Webhook) {
 		w.header.Set("Authorization", token)
 	}
 }
 
+// WithSecret sets a secret to sign requests.
+// Signed requests carry X-Mtw-Timestamp and X-Mtw-Signature headers.
+func WithSecret(secret string) Option {
+	return func(w *Webhook) {
+		w.secret = secret
+	}
+}
+
 func WithTimeout(d time.Duration) Option {
 	return func(w *Webhook) {
 		w.Timeout = d
@@ -71,13 +102,22 @@ func WithLogger(l Logger) Option {
 	}
 }
 
+// WithRecorder sets a Recorder that records every delivery attempt.
+func WithRecorder(r Recorder) Option {
+	return func(w *Webhook) {
+		w.recorder = r
+	}
+}
+
 func WithDefault() Option {
 	return func(w *Webhook) {
 		w.id = WebhookID(uuid.New())
 		w.header = http.Header{}
+		w.secret = ""
 		w.method = "GET"
 		w.Timeout = time.Second * 10
 		w.schema = nil
 		w.logger = slog.Default()
+		w.recorder = nullRecorder{}
 	}
 }
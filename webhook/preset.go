@@ -0,0 +1,254 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var ErrUnknownPreset = errors.New("unknown preset")
+
+// PresetVar is a variable of a Preset the user can override.
+type PresetVar struct {
+	Name        string `json:"name"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Integer     bool   `json:"integer,omitempty"`
+	Min         *int   `json:"min,omitempty"` // the least value of an Integer var, unless nil.
+	Max         *int   `json:"max,omitempty"` // the greatest value of an Integer var, unless nil.
+	Description string `json:"description"`
+}
+
+// bound returns a pointer to n for PresetVar.Min and PresetVar.Max.
+func bound(n int) *int {
+	return &n
+}
+
+// Preset fills in a Blueprint for a well-known service.
+// The schema is a text/template whose variables are expanded between `[[` and `]]` beforehand.
+type Preset struct {
+	Name        string
+	Method      string
+	ContentType string
+	Schema      string
+	Vars        []PresetVar
+}
+
+// body is the text of the mail, or the HTML converted into Markdown if the mail has no text.
+const body = `(default (htmlToMarkdown .HTML) (trim .Text))`
+
+var presets = []Preset{
+	{
+		Name:        "slack",
+		Method:      http.MethodPost,
+		ContentType: ContentTypeJson,
+		Schema: `{"text": {{json (limit [[.limit]] (printf "*%s*\n%s" .Subject ` + body + `))}}` +
+			`[[if .username]], "username": [[literal .username]][[end]]` +
+			`[[if .icon_emoji]], "icon_emoji": [[literal .icon_emoji]][[end]]` +
+			`[[if .channel]], "channel": [[literal .channel]][[end]]}`,
+		Vars: []PresetVar{
+			{Name: "limit", Default: "3000", Integer: true, Min: bound(1), Description: "the max length of the text"},
+			{Name: "username", Description: "the name to post as"},
+			{Name: "icon_emoji", Description: "the emoji to post with, e.g. :mailbox:"},
+			{Name: "channel", Description: "the channel to post to instead of the default"},
+		},
+	},
+	{
+		Name:        "discord",
+		Method:      http.MethodPost,
+		ContentType: ContentTypeJson,
+		Schema: `{"content": {{json (limit [[.limit]] (printf "**%s**\n%s" .Subject ` + body + `))}}` +
+			`[[if .username]], "username": [[literal .username]][[end]]` +
+			`[[if .avatar_url]], "avatar_url": [[literal .avatar_url]][[end]]}`,
+		Vars: []PresetVar{
+			{Name: "limit", Default: "2000", Integer: true, Min: bound(1), Max: bound(2000), Description: "the max length of the content, at most 2000"},
+			{Name: "username", Description: "the name to post as"},
+			{Name: "avatar_url", Description: "the avatar to post with"},
+		},
+	},
+	{
+		Name:        "teams",
+		Method:      http.MethodPost,
+		ContentType: ContentTypeJson,
+		Schema: `{"@type": "MessageCard", "@context": "https://schema.org/extensions", ` +
+			`"themeColor": [[literal .theme_color]], ` +
+			`"summary": {{json (default "(no subject)" .Subject)}}, ` +
+			`"title": {{json .Subject}}, ` +
+			`"text": {{json (limit [[.limit]] ` + body + `)}}}`,
+		Vars: []PresetVar{
+			{Name: "limit", Default: "20000", Integer: true, Min: bound(1), Description: "the max length of the text"},
+			{Name: "theme_color", Default: "0076D7", Description: "the color of the card in hex"},
+		},
+	},
+	{
+		Name:        "mattermost",
+		Method:      http.MethodPost,
+		ContentType: ContentTypeJson,
+		Schema: `{"text": {{json (limit [[.limit]] (printf "#### %s\n%s" .Subject ` + body + `))}}` +
+			`[[if .username]], "username": [[literal .username]][[end]]` +
+			`[[if .icon_url]], "icon_url": [[literal .icon_url]][[end]]` +
+			`[[if .channel]], "channel": [[literal .channel]][[end]]}`,
+		Vars: []PresetVar{
+			{Name: "limit", Default: "16383", Integer: true, Min: bound(1), Max: bound(16383), Description: "the max length of the text, at most 16383"},
+			{Name: "username", Description: "the name to post as"},
+			{Name: "icon_url", Description: "the icon to post with"},
+			{Name: "channel", Description: "the channel to post to instead of the default"},
+		},
+	},
+	{
+		Name:        "ntfy",
+		Method:      http.MethodPost,
+		ContentType: ContentTypeJson,
+		Schema: `{"topic": [[literal .topic]], ` +
+			`"title": {{json .Subject}}, ` +
+			`"message": {{json (limit [[.limit]] ` + body + `)}}, ` +
+			`"priority": [[.priority]]` +
+			`[[if .tags]], "tags": [[tags .tags]][[end]]}`,
+		Vars: []PresetVar{
+			{Name: "topic", Required: true, Description: "the topic to publish to. The endpoint is the root of the server, e.g. https://ntfy.sh"},
+			{Name: "limit", Default: "4000", Integer: true, Min: bound(1), Description: "the max length of the message"},
+			{Name: "priority", Default: "3", Integer: true, Min: bound(1), Max: bound(5), Description: "the priority from 1 to 5"},
+			{Name: "tags", Description: "comma separated tags or emojis, e.g. warning,mailbox"},
+		},
+	},
+	{
+		Name:        "generic-json",
+		Method:      http.MethodPost,
+		ContentType: ContentTypeJson,
+		Schema: `{"id": {{json .ID}}, ` +
+			`"sender": {{json .SenderAddress}}, ` +
+			`"rcpt": {{json .RcptAddress}}, ` +
+			`"from": {{json .From}}, ` +
+			`"to": {{json .To}}, ` +
+			`"cc": {{json .Cc}}, ` +
+			`"subject": {{json .Subject}}, ` +
+			`"date": {{json (date "2006-01-02T15:04:05Z07:00" .Date)}}, ` +
+			`"message_id": {{json .MessageID}}, ` +
+			`"text": {{json .Text}}, ` +
+			`"html": {{json .HTML}}, ` +
+			`"attachments": [{{range $i, $a := .Attachments}}{{if $i}}, {{end}}` +
+			`{"filename": {{json $a.Filename}}, "content_type": {{json $a.ContentType}}, "size": {{$a.Size}}}{{end}}]}`,
+	},
+}
+
+// Presets returns all the built-in Presets.
+func Presets() []Preset {
+	return presets
+}
+
+// FindPreset returns the Preset named `name`.
+//
+// # Errors
+//   - ErrUnknownPreset if no Preset has the name.
+func FindPreset(name string) (*Preset, error) {
+	for _, p := range presets {
+		if p.Name == name {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownPreset, name)
+}
+
+var presetFuncs = template.FuncMap{
+	// literal encodes a string into a JSON string literal that is kept as is by the schema.
+	"literal": func(s string) (string, error) {
+		b, err := json.Marshal(s)
+		if err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(string(b), "{{", `{{"{{"}}`), nil
+	},
+	// tags encodes comma separated values into a JSON array of strings.
+	"tags": func(s string) (string, error) {
+		var tags []string
+		for _, tag := range strings.Split(s, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		b, err := json.Marshal(tags)
+		if err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(string(b), "{{", `{{"{{"}}`), nil
+	},
+}
+
+// Apply fills in the method, the content type and the schema of the Blueprint
+// unless they are set already. The vars override the defaults of the Preset.
+//
+// # Errors
+//   - ValidationError for unknown, missing or malformed vars.
+func (p Preset) Apply(bp Blueprint, vars map[string]string) (*Blueprint, error) {
+	values, err := p.values(vars)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(p.Name).Delims("[[", "]]").Funcs(presetFuncs).Parse(p.Schema)
+	if err != nil {
+		return nil, err
+	}
+	var schema bytes.Buffer
+	if err := tmpl.Execute(&schema, values); err != nil {
+		return nil, err
+	}
+	if bp.Method == "" {
+		bp.Method = p.Method
+	}
+	if bp.ContentType == "" {
+		bp.ContentType = p.ContentType
+	}
+	if bp.Schema == "" {
+		bp.Schema = schema.String()
+		bp.SchemaMode = SchemaModeText
+	}
+	return &bp, nil
+}
+
+// values returns the vars with the defaults.
+func (p Preset) values(vars map[string]string) (map[string]string, error) {
+	errs := ValidationError{}
+	known := map[string]bool{}
+	values := map[string]string{}
+	for _, v := range p.Vars {
+		known[v.Name] = true
+		value, ok := vars[v.Name]
+		if !ok || value == "" {
+			value = v.Default
+		}
+		if value == "" && v.Required {
+			errs["vars."+v.Name] = "is required"
+			continue
+		}
+		if v.Integer {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				errs["vars."+v.Name] = "has to be an integer"
+				continue
+			}
+			if v.Min != nil && n < *v.Min {
+				errs["vars."+v.Name] = fmt.Sprintf("has to be at least %d", *v.Min)
+				continue
+			}
+			if v.Max != nil && n > *v.Max {
+				errs["vars."+v.Name] = fmt.Sprintf("has to be at most %d", *v.Max)
+				continue
+			}
+		}
+		values[v.Name] = value
+	}
+	for name := range vars {
+		if !known[name] {
+			errs["vars."+name] = fmt.Sprintf("is not a variable of the preset %s", p.Name)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return values, nil
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresets(t *testing.T) {
+	vars := map[string]map[string]string{
+		"ntfy": {"topic": "alerts", "tags": "warning, mailbox"},
+	}
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			bp, err := p.Apply(Blueprint{Endpoint: "https://example.local/hook"}, vars[p.Name])
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.NoError(t, bp.Validate())
+
+			wh, err := FromBlueprint(*bp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := wh.PrepareRequest(quotedTransaction())
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(req.Body)
+			assert.True(t, json.Valid(body), string(body))
+		})
+	}
+}
+
+func TestPreset_Vars(t *testing.T) {
+	slack, err := FindPreset("slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bp, err := slack.Apply(Blueprint{Endpoint: "https://example.local/hook"}, map[string]string{
+		"limit":    "10",
+		"username": `mtw "{{.Subject}}"`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wh, err := FromBlueprint(*bp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := wh.PrepareRequest(quotedTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	assert.Equal(t, `{"text": "*Re: \"quot", "username": "mtw \"{{.Subject}}\""}`, string(body))
+
+	_, err = slack.Apply(Blueprint{}, map[string]string{"limit": "many", "color": "red"})
+	var invalid ValidationError
+	if assert.ErrorAs(t, err, &invalid) {
+		assert.Equal(t, ValidationError{
+			"vars.limit": "has to be an integer",
+			"vars.color": "is not a variable of the preset slack",
+		}, invalid)
+	}
+
+	ntfy, _ := FindPreset("ntfy")
+	_, err = ntfy.Apply(Blueprint{}, nil)
+	assert.ErrorIs(t, err, ErrInvalidBlueprint)
+
+	_, err = slack.Apply(Blueprint{}, map[string]string{"limit": "-1"})
+	assert.Equal(t, ValidationError{"vars.limit": "has to be at least 1"}, err)
+	_, err = ntfy.Apply(Blueprint{}, map[string]string{"topic": "mtw", "priority": "9"})
+	assert.Equal(t, ValidationError{"vars.priority": "has to be at most 5"}, err)
+	discord, _ := FindPreset("discord")
+	_, err = discord.Apply(Blueprint{}, map[string]string{"limit": "5000"})
+	assert.Equal(t, ValidationError{"vars.limit": "has to be at most 2000"}, err)
+	_, err = discord.Apply(Blueprint{}, map[string]string{"limit": "2000"})
+	assert.NoError(t, err)
+
+	_, err = FindPreset("irc")
+	assert.ErrorIs(t, err, ErrUnknownPreset)
+}
+
+func TestPreset_KeepsBlueprint(t *testing.T) {
+	slack, _ := FindPreset("slack")
+	bp, err := slack.Apply(Blueprint{Endpoint: "https://example.local/hook", Schema: `{"text":"hi"}`, Method: "PUT"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `{"text":"hi"}`, bp.Schema)
+	assert.Equal(t, "", bp.SchemaMode)
+	assert.Equal(t, "PUT", bp.Method)
+	assert.True(t, strings.HasPrefix(bp.ContentType, ContentTypeJson))
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"text/template"
+
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+const (
+	// SchemaModeHTML renders schemas with html/template. It is the default for compatibility.
+	SchemaModeHTML string = "html"
+	// SchemaModeText renders schemas with text/template and the JSON-safe helpers.
+	SchemaModeText string = "text"
+)
+
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// SchemaError is returned when a schema fails to render a Transaction.
+// Rendering the same Transaction again fails the same way, so it is not retryable.
+type SchemaError struct {
+	Err error
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("failed to render the schema: %s", e.Err)
+}
+
+func (e SchemaError) Unwrap() error {
+	return e.Err
+}
+
+func (e SchemaError) Retryable() bool {
+	return false
+}
+
+// executor is implemented by both html/template and text/template.
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// schema is a template of request bodies.
+type schema struct {
+	mode   string
+	source string
+	tmpl   executor
+}
+
+// parseSchema parses the source in the mode. An empty mode means SchemaModeHTML.
+//
+// # Errors
+//   - ErrInvalidSchema if the mode is unknown or the source is malformed.
+func parseSchema(source string, mode string) (*schema, error) {
+	var tmpl executor
+	var err error
+	switch mode {
+	case "", SchemaModeHTML:
+		tmpl, err = htmltemplate.New("").Funcs(tmplFuncs).Parse(source)
+	case SchemaModeText:
+		tmpl, err = template.New("").Funcs(textFuncs).Parse(source)
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidSchema, mode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	return &schema{mode: mode, source: source, tmpl: tmpl}, nil
+}
+
+// render executes the schema with the Transaction.
+// In SchemaModeText, the output has to be a valid JSON if the content type is JSON.
+func (s schema) render(t session.Transaction, contentType string) (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	if err := s.tmpl.Execute(buf, t); err != nil {
+		return nil, SchemaError{err}
+	}
+	if s.mode == SchemaModeText && isJSON(contentType) && !json.Valid(buf.Bytes()) {
+		return nil, SchemaError{fmt.Errorf("rendered an invalid JSON: %s", limit(256, buf.String()))}
+	}
+	return buf, nil
+}
+
+func isJSON(contentType string) bool {
+	mediatype, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediatype == ContentTypeJson
+}
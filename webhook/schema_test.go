@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+func quotedTransaction() session.Transaction {
+	mail := "From: alice<alice@mail.com>\n" +
+		"To: bob<bob@mail.com>\n" +
+		"Subject: =?UTF-8?Q?Re:_\"quoted\"_<tag>_#123?=\n" +
+		"Date: Mon, 02 Jan 2006 15:04:05 +0000\n" +
+		"\n" +
+		"  line 1\n\"line 2\"\n"
+	t, err := session.NewTransaction(
+		uuid.New(),
+		session.MustParseAddr("alice@mail.com"),
+		session.MustParseAddr("bob@mail.com"),
+		strings.NewReader(mail),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return *t
+}
+
+func render(t *testing.T, schema string, contentType string) (string, error) {
+	wh, err := FromBlueprint(Blueprint{
+		Endpoint:    "http://example.local",
+		Method:      "POST",
+		Schema:      schema,
+		SchemaMode:  SchemaModeText,
+		ContentType: contentType,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := wh.PrepareRequest(quotedTransaction())
+	if err != nil {
+		return "", err
+	}
+	body, _ := io.ReadAll(req.Body)
+	return string(body), nil
+}
+
+func TestTextSchema_Helpers(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{`{"text":{{json .Text}}}`, `{"text":"  line 1\n\"line 2\"\n"}`},
+		{`{"text":"> {{jsonString .Subject}}"}`, `{"text":"> Re: \"quoted\" <tag> #123"}`},
+		{`{"text":{{json (trim .Text)}}}`, `{"text":"line 1\n\"line 2\""}`},
+		{`{"from":"{{lower .SenderAddress | base64}}"}`, `{"from":"YWxpY2VAbWFpbC5jb20="}`},
+		{`{"issue":"{{regexFind "#[0-9]+" .Subject}}"}`, `{"issue":"#123"}`},
+		{`{"cc":"{{default "nobody" .Cc}}"}`, `{"cc":"nobody"}`},
+		{`{"date":"{{date "2006-01-02" .Date}}"}`, `{"date":"2006-01-02"}`},
+		{`{"short":"{{limit 3 .SenderAddress}}"}`, `{"short":"ali"}`},
+		{`{"short":"{{limit -1 .SenderAddress}}"}`, `{"short":""}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			body, err := render(t, tt.schema, ContentTypeJson)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, body)
+		})
+	}
+}
+
+func TestTextSchema_InvalidJSON(t *testing.T) {
+	_, err := render(t, `{"text":"{{.Text}}"}`, ContentTypeJson)
+	var schemaErr SchemaError
+	if assert.ErrorAs(t, err, &schemaErr) {
+		assert.False(t, schemaErr.Retryable())
+	}
+
+	// only JSON is validated.
+	body, err := render(t, `{{.SenderAddress}}`, "text/plain")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@mail.com", body)
+}
+
+func TestTextSchema_ExecError(t *testing.T) {
+	_, err := render(t, `{{regexFind "(" .Subject}}`, "text/plain")
+	assert.ErrorAs(t, err, &SchemaError{})
+}
+
+func TestSchemaMode_Blueprint(t *testing.T) {
+	bp := Blueprint{
+		ID:          "ece24b02-c98f-46b2-993f-a0860cd116cd",
+		Endpoint:    "http://example.local",
+		Method:      "POST",
+		Schema:      `{"msg":{{json .Text}}}`,
+		SchemaMode:  SchemaModeText,
+		ContentType: ContentTypeJson,
+	}
+	wh, err := FromBlueprint(bp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, bp, wh.IntoBlueprint())
+
+	bp.SchemaMode = "jinja"
+	_, err = FromBlueprint(bp)
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+}
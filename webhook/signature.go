@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderSignature string = "X-Mtw-Signature"
+	HeaderTimestamp string = "X-Mtw-Timestamp"
+
+	signaturePrefix string = "sha256="
+)
+
+// NewSecret returns a random secret to sign requests.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature of the body sent at the timestamp (unix seconds).
+// The signature is a hex encoded HMAC-SHA256 over `<timestamp>.<body>`,
+// e.g. sha256=499b9603792fb66953348c0ede95d4e359bcd65afade35b707233244c14275af
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the body sent at the timestamp.
+// It is what a receiver has to do with the headers of a signed request.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
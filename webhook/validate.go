@@ -0,0 +1,334 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/google/uuid"
+	"github.com/zen-en-tonal/mtw/session"
+)
+
+var ErrInvalidBlueprint = errors.New("invalid blueprint")
+
+// ValidationError maps the fields of a Blueprint to why they are invalid.
+// The fields are named as in the API, e.g. `content_type`.
+type ValidationError map[string]string
+
+func (e ValidationError) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for i, field := range fields {
+		fields[i] = field + ": " + e[field]
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidBlueprint, strings.Join(fields, ", "))
+}
+
+func (e ValidationError) Is(target error) bool {
+	return target == ErrInvalidBlueprint
+}
+
+var methods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// sampleMail is rendered by schemas to check their output.
+const sampleMail = "From: Alice <alice@mail.com>\r\n" +
+	"To: Bob <bob@mail.com>\r\n" +
+	"Subject: sample\r\n" +
+	"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+	"Message-ID: <sample@mail.com>\r\n" +
+	"\r\n" +
+	"sample\r\n"
+
+// Validate checks every field of the Blueprint.
+// The schema has to refer only to fields that exist on session.Transaction,
+// and has to render a JSON object for multipart/form-data,
+// or a JSON for a JSON content type in SchemaModeText.
+//
+// # Errors
+//   - ValidationError, which is ErrInvalidBlueprint, with the invalid fields.
+func (b Blueprint) Validate() error {
+	errs := ValidationError{}
+	if b.ID != "" {
+		if _, err := uuid.Parse(b.ID); err != nil {
+			errs["id"] = err.Error()
+		}
+	}
+	if msg := validateEndpoint(b.Endpoint); msg != "" {
+		errs["endpoint"] = msg
+	}
+	if b.Method != "" && !methods[b.Method] {
+		errs["method"] = fmt.Sprintf("%q is not one of GET, HEAD, POST, PUT, PATCH and DELETE", b.Method)
+	}
+	mediatype := ""
+	if b.ContentType != "" {
+		mt, _, err := mime.ParseMediaType(b.ContentType)
+		if err != nil {
+			errs["content_type"] = err.Error()
+		}
+		mediatype = mt
+	}
+	switch b.SchemaMode {
+	case "", SchemaModeHTML, SchemaModeText:
+	default:
+		errs["schema_mode"] = fmt.Sprintf("%q is not one of %s and %s", b.SchemaMode, SchemaModeHTML, SchemaModeText)
+	}
+	if b.Schema != "" && errs["schema_mode"] == "" {
+		if b.ContentType == "" {
+			errs["content_type"] = "is required with a schema"
+		}
+		if msg := validateSchema(b.Schema, b.SchemaMode, mediatype); msg != "" {
+			errs["schema"] = msg
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateEndpoint(endpoint string) string {
+	if endpoint == "" {
+		return "is required"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err.Error()
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "has to be an http or https URL"
+	}
+	if u.Host == "" {
+		return "has no host"
+	}
+	return ""
+}
+
+func validateSchema(source string, mode string, mediatype string) string {
+	s, err := parseSchema(source, mode)
+	if err != nil {
+		return strings.TrimPrefix(err.Error(), ErrInvalidSchema.Error()+": ")
+	}
+	if err := checkFields(s.tree(), reflect.TypeOf(session.Transaction{})); err != nil {
+		return err.Error()
+	}
+	// html/template does not escape for JSON, so only SchemaModeText is validated as JSON.
+	if !(mediatype == ContentTypeJson && mode == SchemaModeText) && mediatype != ContentTypeMultipart {
+		return ""
+	}
+	sample, err := session.NewTransaction(
+		uuid.Nil,
+		session.MustParseAddr("alice@mail.com"),
+		session.MustParseAddr("bob@mail.com"),
+		strings.NewReader(sampleMail),
+	)
+	if err != nil {
+		panic(err)
+	}
+	buf, err := s.render(*sample, "")
+	if err != nil {
+		// it may depend on the mail, e.g. indexing attachments.
+		return ""
+	}
+	if mediatype == ContentTypeMultipart {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(buf.Bytes(), &object); err != nil {
+			return "has to render a JSON object for multipart/form-data"
+		}
+		return ""
+	}
+	if !json.Valid(buf.Bytes()) {
+		return fmt.Sprintf("renders an invalid JSON for %s: %s", ContentTypeJson, limit(256, buf.String()))
+	}
+	return ""
+}
+
+// tree returns the parsed tree of the schema.
+func (s schema) tree() *parse.Tree {
+	switch t := s.tmpl.(type) {
+	case *template.Template:
+		return t.Tree
+	case *htmltemplate.Template:
+		return t.Tree
+	}
+	return nil
+}
+
+// checkFields returns an error if the tree refers to a field or a method that does not exist.
+// The types of dot are followed through range and with, and unknown types are not checked.
+func checkFields(tree *parse.Tree, root reflect.Type) error {
+	if tree == nil || tree.Root == nil {
+		return nil
+	}
+	return fieldChecker{root}.walk(tree.Root, root)
+}
+
+type fieldChecker struct {
+	root reflect.Type // the type of `$`.
+}
+
+func (c fieldChecker) walk(node parse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := c.walk(child, dot); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		_, err := c.pipe(n.Pipe, dot)
+		return err
+	case *parse.TemplateNode:
+		_, err := c.pipe(n.Pipe, dot)
+		return err
+	case *parse.IfNode:
+		return c.branch(&n.BranchNode, dot, func(reflect.Type) reflect.Type { return dot })
+	case *parse.WithNode:
+		return c.branch(&n.BranchNode, dot, func(t reflect.Type) reflect.Type { return t })
+	case *parse.RangeNode:
+		return c.branch(&n.BranchNode, dot, elem)
+	}
+	return nil
+}
+
+// branch walks the list with dot the inner function returns for the type of the pipeline.
+func (c fieldChecker) branch(n *parse.BranchNode, dot reflect.Type, inner func(reflect.Type) reflect.Type) error {
+	t, err := c.pipe(n.Pipe, dot)
+	if err != nil {
+		return err
+	}
+	if err := c.walk(n.List, inner(t)); err != nil {
+		return err
+	}
+	return c.walk(n.ElseList, dot)
+}
+
+// pipe checks the pipeline and returns its type, or nil if unknown.
+func (c fieldChecker) pipe(p *parse.PipeNode, dot reflect.Type) (reflect.Type, error) {
+	if p == nil {
+		return nil, nil
+	}
+	var last reflect.Type
+	for _, cmd := range p.Cmds {
+		last = nil
+		for i, arg := range cmd.Args {
+			t, err := c.arg(arg, dot)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 && len(cmd.Args) == 1 {
+				last = t
+			}
+		}
+		if len(cmd.Args) > 1 {
+			if f, ok := cmd.Args[0].(*parse.FieldNode); ok {
+				// a method called with arguments, e.g. `.Header "X-Priority"`.
+				last, _ = c.resolve(dot, f.Ident)
+			}
+		}
+	}
+	return last, nil
+}
+
+func (c fieldChecker) arg(arg parse.Node, dot reflect.Type) (reflect.Type, error) {
+	switch a := arg.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return c.resolve(dot, a.Ident)
+	case *parse.VariableNode:
+		if a.Ident[0] == "$" {
+			return c.resolve(c.root, a.Ident[1:])
+		}
+	case *parse.PipeNode:
+		return c.pipe(a, dot)
+	case *parse.ChainNode:
+		if p, ok := a.Node.(*parse.PipeNode); ok {
+			t, err := c.pipe(p, dot)
+			if err != nil {
+				return nil, err
+			}
+			return c.resolve(t, a.Field)
+		}
+	}
+	return nil, nil
+}
+
+// resolve returns the type of the chain of fields on t, or nil if unknown.
+func (c fieldChecker) resolve(t reflect.Type, idents []string) (reflect.Type, error) {
+	for _, name := range idents {
+		if t == nil {
+			return nil, nil
+		}
+		if m, ok := t.MethodByName(name); ok {
+			t = out(m.Type)
+			continue
+		}
+		if m, ok := reflect.PointerTo(t).MethodByName(name); ok && t.Kind() != reflect.Pointer {
+			t = out(m.Type)
+			continue
+		}
+		base := t
+		if base.Kind() == reflect.Pointer {
+			base = base.Elem()
+		}
+		switch base.Kind() {
+		case reflect.Struct:
+			f, ok := base.FieldByName(name)
+			if !ok || !f.IsExported() {
+				return nil, fmt.Errorf("%s has no field or method %s", base, name)
+			}
+			t = f.Type
+		case reflect.Map:
+			t = base.Elem()
+		case reflect.Interface:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("%s has no field or method %s", base, name)
+		}
+	}
+	return t, nil
+}
+
+// out returns the type a method returns, or nil if it returns nothing.
+func out(method reflect.Type) reflect.Type {
+	if method.NumOut() == 0 {
+		return nil
+	}
+	return method.Out(0)
+}
+
+// elem returns the type of elements of range over t, or nil if unknown.
+func elem(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return t.Elem()
+	case reflect.Int:
+		return t
+	}
+	return nil
+}
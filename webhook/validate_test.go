@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlueprint_Validate(t *testing.T) {
+	valid := Blueprint{
+		Endpoint:    "https://example.local/hook",
+		Method:      "POST",
+		Schema:      `{"text":{{json .Text}},"files":[{{range $i, $a := .Attachments}}{{if $i}},{{end}}{{json .Filename}}{{end}}],"reason":{{json (.Header "X-GitHub-Reason")}},"year":{{.Date.Year}},"to":[{{range .ToAddresses}}{{json .Address}}{{end}}]}`,
+		SchemaMode:  SchemaModeText,
+		ContentType: "application/json; charset=utf-8",
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, Blueprint{Endpoint: "http://example.local"}.Validate())
+
+	tests := []struct {
+		name  string
+		bp    func(bp Blueprint) Blueprint
+		field string
+	}{
+		{"no endpoint", func(bp Blueprint) Blueprint { bp.Endpoint = ""; return bp }, "endpoint"},
+		{"scheme", func(bp Blueprint) Blueprint { bp.Endpoint = "ftp://example.local"; return bp }, "endpoint"},
+		{"no host", func(bp Blueprint) Blueprint { bp.Endpoint = "http:///hook"; return bp }, "endpoint"},
+		{"method", func(bp Blueprint) Blueprint { bp.Method = "SEND"; return bp }, "method"},
+		{"id", func(bp Blueprint) Blueprint { bp.ID = "1"; return bp }, "id"},
+		{"mode", func(bp Blueprint) Blueprint { bp.SchemaMode = "jinja"; return bp }, "schema_mode"},
+		{"no content type", func(bp Blueprint) Blueprint { bp.ContentType = ""; return bp }, "content_type"},
+		{"parse", func(bp Blueprint) Blueprint { bp.Schema = `{{.Text`; return bp }, "schema"},
+		{"field", func(bp Blueprint) Blueprint { bp.Schema = `{"text":{{json .Body}}}`; return bp }, "schema"},
+		{"nested field", func(bp Blueprint) Blueprint {
+			bp.Schema = `[{{range .Attachments}}{{json .Name}}{{end}}]`
+			return bp
+		}, "schema"},
+		{"root field", func(bp Blueprint) Blueprint {
+			bp.Schema = `[{{range .Attachments}}{{json $.Sender}}{{end}}]`
+			return bp
+		}, "schema"},
+		{"unexported", func(bp Blueprint) Blueprint { bp.Schema = `{{json .raw}}`; return bp }, "schema"},
+		{"invalid json", func(bp Blueprint) Blueprint { bp.Schema = `{"text":"{{.Text}}"}`; return bp }, "schema"},
+		{"not object", func(bp Blueprint) Blueprint {
+			bp.ContentType = ContentTypeMultipart
+			bp.Schema = `[]`
+			return bp
+		}, "schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.bp(valid).Validate()
+			assert.ErrorIs(t, err, ErrInvalidBlueprint)
+			var invalid ValidationError
+			if assert.ErrorAs(t, err, &invalid) {
+				assert.Contains(t, invalid, tt.field)
+				assert.Len(t, invalid, 1, invalid.Error())
+			}
+		})
+	}
+}
+
+func TestBlueprint_Validate_HTMLMode(t *testing.T) {
+	bp := Blueprint{
+		Endpoint:    "https://example.local/hook",
+		Method:      "POST",
+		Schema:      `{"msg":"{{Limit 10 .Text}}"}`,
+		ContentType: ContentTypeJson,
+	}
+	assert.NoError(t, bp.Validate())
+	bp.Schema = `{"msg":"{{.Txt}}"}`
+	assert.ErrorIs(t, bp.Validate(), ErrInvalidBlueprint)
+}
@@ -1,18 +1,21 @@
 package webhook
 
 import (
-	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"html/template"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/zen-en-tonal/mtw/session"
 )
 
 const (
-	ContentTypeJson string = "application/json"
+	ContentTypeJson      string = "application/json"
+	ContentTypeMultipart string = "multipart/form-data"
 )
 
 type Option func(*Webhook)
@@ -29,14 +32,37 @@ func (i WebhookID) String() string {
 	return uuid.UUID(i).String()
 }
 
+// StatusError is returned by Send when the endpoint responds with an error status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf(
+		"sent an http request but it responded with an error status '%s'",
+		e.Status,
+	)
+}
+
+// Retryable reports whether the request is worth retrying.
+// Server errors, timeouts and rate limits are retryable, other client errors are not.
+func (e StatusError) Retryable() bool {
+	return e.StatusCode >= 500 ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests
+}
+
 type Webhook struct {
 	http.Client
 	id       WebhookID
 	endpoint string
 	method   string
 	header   http.Header
-	schema   *template.Template
+	secret   string
+	schema   *schema
 	logger   Logger
+	recorder Recorder
 }
 
 func New(endpoint string, options ...Option) Webhook {
@@ -48,13 +74,15 @@ func New(endpoint string, options ...Option) Webhook {
 	return w
 }
 
+// FromBlueprint builds a Webhook from the Blueprint.
+// Use Blueprint.Validate to check the Blueprint thoroughly before saving it.
 func FromBlueprint(bp Blueprint, defaults ...Option) (*Webhook, error) {
 	options, err := bp.options(defaults...)
 	if err != nil {
 		return nil, err
 	}
 	if bp.Endpoint == "" {
-		return nil, fmt.Errorf("")
+		return nil, ValidationError{"endpoint": "is required"}
 	}
 	wh := New(bp.Endpoint, *options...)
 	return &wh, nil
@@ -62,17 +90,19 @@ func FromBlueprint(bp Blueprint, defaults ...Option) (*Webhook, error) {
 
 // IntoBlueprint returns a Blueprint that is reconstructable this Webhook.
 func (w Webhook) IntoBlueprint() Blueprint {
-	schema := ""
+	source, mode := "", ""
 	if w.schema != nil {
-		schema = w.schema.Tree.Root.String()
+		source, mode = w.schema.source, w.schema.mode
 	}
 	return Blueprint{
 		ID:          uuid.UUID(w.ID()).String(),
 		Endpoint:    w.endpoint,
 		Method:      w.method,
 		Auth:        w.header.Get("Authorization"),
-		Schema:      schema,
+		Schema:      source,
+		SchemaMode:  mode,
 		ContentType: w.header.Get("Content-Type"),
+		Secret:      w.secret,
 	}
 }
 
@@ -80,18 +110,55 @@ func (e Webhook) ID() WebhookID {
 	return e.id
 }
 
+// Send sends an http request built from the Transaction.
+// Every attempt is recorded by the Recorder.
 func (w Webhook) Send(t session.Transaction) error {
+	d := Delivery{
+		ID:            uuid.New(),
+		TransactionID: t.ID,
+		WebhookID:     w.id,
+		Address:       t.RcptAddress(),
+		CreatedAt:     time.Now().UTC(),
+	}
+	err := w.send(t, &d)
+	if err != nil {
+		d.Error = err.Error()
+	}
+	if err := w.recorder.Record(d); err != nil {
+		w.logger.Error(
+			"failed to record the delivery",
+			"ID", t.ID.String(),
+			"WebhookID", w.id.String(),
+			"inner", err,
+		)
+	}
+	return err
+}
+
+func (w Webhook) send(t session.Transaction, d *Delivery) error {
 	req, err := w.PrepareRequest(t)
 	if err != nil {
 		return err
 	}
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(body)
+	d.BodyHash = hex.EncodeToString(hash[:])
+
+	start := time.Now()
 	resp, err := w.Do(req)
+	d.Latency = time.Since(start)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseLength))
+	d.StatusCode = resp.StatusCode
+	d.Response = string(msg)
+
 	if resp.StatusCode >= 400 {
-		msg := new(bytes.Buffer)
-		msg.ReadFrom(resp.Body)
 		w.logger.Error(
 			"sent an http request but it responded with an error status",
 			"ID", t.ID.String(),
@@ -100,12 +167,9 @@ func (w Webhook) Send(t session.Transaction) error {
 			"Method", resp.Request.Method,
 			"StatusCode", resp.StatusCode,
 			"Status", resp.Status,
-			"Msg", msg.String(),
-		)
-		return fmt.Errorf(
-			"sent an http request but it responded with an error status '%s'",
-			resp.Status,
+			"Msg", string(msg),
 		)
+		return StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 	w.logger.Info(
 		"sent an http request with successed",
@@ -122,8 +186,16 @@ func (w Webhook) Send(t session.Transaction) error {
 // PrepareRequest returns the `http.Request` or an error using `session.Transaction`.
 func (w Webhook) PrepareRequest(t session.Transaction) (*http.Request, error) {
 	var body io.Reader = nil
-	if w.schema != nil {
-		r, err := execTemplate(*w.schema, t)
+	header := w.header.Clone()
+	if w.isMultipart() {
+		r, contentType, err := w.formData(t)
+		if err != nil {
+			return nil, err
+		}
+		body = r
+		header.Set("Content-Type", contentType)
+	} else if w.schema != nil {
+		r, err := w.schema.render(t, w.header.Get("Content-Type"))
 		if err != nil {
 			return nil, err
 		}
@@ -133,14 +205,35 @@ func (w Webhook) PrepareRequest(t session.Transaction) (*http.Request, error) {
 	if err != nil {
 		return nil, err
 	}
-	req.Header = w.header
+	req.Header = header
+	if w.secret != "" {
+		if err := w.sign(req, time.Now().Unix()); err != nil {
+			return nil, err
+		}
+	}
 	return req, nil
 }
 
-func execTemplate(tmpl template.Template, t session.Transaction) (io.Reader, error) {
-	buf := new(bytes.Buffer)
-	if err := tmpl.Execute(buf, t); err != nil {
+// sign sets the timestamp and the signature of the request body into the headers.
+func (w Webhook) sign(req *http.Request, timestamp int64) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(w.secret, timestamp, body))
+	return nil
+}
+
+// readBody returns a copy of the request body without consuming it.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.GetBody == nil {
+		return []byte{}, nil
+	}
+	r, err := req.GetBody()
+	if err != nil {
 		return nil, err
 	}
-	return buf, nil
+	defer r.Close()
+	return io.ReadAll(r)
 }
@@ -2,6 +2,9 @@ package webhook
 
 import (
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -69,6 +72,7 @@ func Test_IntoBlueprint(t *testing.T) {
 		Schema:      `{"msg":"{{.Text}}"}`,
 		ContentType: "application/json",
 		Auth:        "sercret",
+		Secret:      "signing-secret",
 	}
 	wh, err := FromBlueprint(bp)
 	if err != nil {
@@ -148,3 +152,72 @@ func Test_TemplateFunction_Escape(t *testing.T) {
 	req.Body.Read(buf)
 	assert.Equal(t, "{\"msg\":\"hello\n\"}", string(buf))
 }
+
+type spyRecorder struct {
+	res []Delivery
+}
+
+func (r *spyRecorder) Record(d Delivery) error {
+	r.res = append(r.res, d)
+	return nil
+}
+
+func Test_Send_Record(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("bad gateway"))
+	}))
+	defer server.Close()
+
+	recorder := spyRecorder{}
+	wh := New(server.URL, WithRecorder(&recorder))
+	trans := testTransaction("hello")
+	err := wh.Send(trans)
+
+	assert.ErrorAs(t, err, &StatusError{})
+	assert.Len(t, recorder.res, 1)
+	d := recorder.res[0]
+	assert.Equal(t, trans.ID, d.TransactionID)
+	assert.Equal(t, wh.ID(), d.WebhookID)
+	assert.Equal(t, "bob@mail.com", d.Address)
+	assert.Equal(t, http.StatusBadGateway, d.StatusCode)
+	assert.Equal(t, "bad gateway", d.Response)
+	assert.Equal(t, err.Error(), d.Error)
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", d.BodyHash)
+}
+
+func Test_Signature(t *testing.T) {
+	bp := Blueprint{
+		Endpoint:    "http://example.local",
+		Method:      "POST",
+		Schema:      `{"msg":"{{.Text}}"}`,
+		ContentType: "application/json",
+		Secret:      "signing-secret",
+	}
+	wh, err := FromBlueprint(bp)
+	if err != nil {
+		t.Error(err)
+	}
+	req, err := wh.PrepareRequest(testTransaction("hello"))
+	if err != nil {
+		t.Error(err)
+	}
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Error(err)
+	}
+	signature := req.Header.Get(HeaderSignature)
+
+	assert.True(t, Verify("signing-secret", timestamp, []byte(`{"msg":"hello"}`), signature))
+	assert.False(t, Verify("other-secret", timestamp, []byte(`{"msg":"hello"}`), signature))
+	assert.False(t, Verify("signing-secret", timestamp+1, []byte(`{"msg":"hello"}`), signature))
+	assert.Empty(t, wh.header.Get(HeaderSignature))
+}
+
+func Test_Sign(t *testing.T) {
+	assert.Equal(
+		t,
+		"sha256=499b9603792fb66953348c0ede95d4e359bcd65afade35b707233244c14275af",
+		Sign("secret", 1700000000, []byte(`{"msg":"hello"}`)),
+	)
+}